@@ -0,0 +1,173 @@
+/*
+Copyright © 2024 koh-sh
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"github.com/koh-sh/actbills/internal/bills"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	configFile      string
+	serveConfigFile string
+
+	// pricing and budget set by the configuration file, which have no flags of their own
+	configPricing      *bills.Pricing
+	configBudget       *bills.Budget
+	serveConfigPricing *bills.Pricing
+)
+
+// applyConfig loads the configuration file of the report and sets the flags of the root command not given on the command line to its values.
+// Flags take precedence over the configuration file, which takes precedence over the environment variables and the defaults.
+func applyConfig(cmd *cobra.Command) error {
+	cfg, err := bills.LoadConfig(configFile)
+	if err != nil {
+		return err
+	}
+	flags := cmd.Flags()
+
+	// the repository, the organization and the repositories are mutually exclusive, so a flag of any replaces all the keys
+	if !flags.Changed("repo") && !flags.Changed("org") && !flags.Changed("repos") {
+		setString(flags, "repo", &repo, cfg.Repository)
+		setString(flags, "org", &org, cfg.Organization)
+		setSlice(flags, "repos", &repos, cfg.Repositories)
+	}
+
+	setString(flags, "format", &format, string(cfg.Format))
+	setString(flags, "output", &output, cfg.Output)
+	setString(flags, "json-report", &jsonReport, cfg.JSONReport)
+	setString(flags, "template", &templateFile, cfg.Template)
+	setString(flags, "sort", &sortKey, string(cfg.Sort))
+	setString(flags, "sort-order", &sortOrder, string(cfg.SortOrder))
+	setValue(flags, "top", &top, cfg.Top)
+	setValue(flags, "top-jobs", &topJobs, cfg.TopJobs)
+
+	setFilter(flags, &filter, cfg.Filter)
+
+	setValue(flags, "larger-runners", &largerRunners, cfg.LargerRunners)
+	if cfg.RunnerSKUs != nil && !flags.Changed("runner-sku") {
+		runnerSKUs = cfg.RunnerSKUs
+	}
+	setValue(flags, "storage", &storage, cfg.Storage)
+
+	if !flags.Changed("pricing") {
+		configPricing = cfg.Pricing
+	}
+	if !flags.Changed("budget") {
+		configBudget = cfg.Budget
+	}
+	setValue(flags, "fail-on-budget", &failOnBudget, cfg.FailOnBudget)
+
+	setValue(flags, "concurrency", &concurrency, cfg.Concurrency)
+	setValue(flags, "collect-errors", &collectErrors, cfg.CollectErrors)
+	setValue(flags, "tolerant", &tolerant, cfg.Tolerant)
+	setValue(flags, "fail-on-partial", &failOnPartial, cfg.FailOnPartial)
+
+	client.applyConfig(flags, cfg)
+
+	setString(flags, "snapshot", &snapshotFile, cfg.Snapshot)
+	setValue(flags, "snapshot-keep", &snapshotKeep, cfg.SnapshotKeep)
+
+	setValue(flags, "billing-cycle-day", &billingCycleDay, cfg.BillingCycleDay)
+	setValue(flags, "forecast", &forecast, cfg.Forecast)
+	setString(flags, "plan", &plan, cfg.Plan)
+
+	setValue(flags, "billing-summary", &billingSummary, cfg.BillingSummary)
+
+	setValue(flags, "comment-on", &commentOn, cfg.CommentOn)
+	setValue(flags, "tracking-issue", &trackingIssue, cfg.TrackingIssue)
+	setString(flags, "publish-repo", &publishRepo, cfg.PublishRepository)
+
+	setValue(flags, "notify-dry-run", &notifyDryRun, cfg.NotifyDryRun)
+	return nil
+}
+
+// applyServeConfig loads the configuration file of the serve command and sets its flags not given on the command line to the values.
+// Only the keys serve has flags for are applied, so the keys of the report output such as format and output are ignored.
+func applyServeConfig(cmd *cobra.Command) error {
+	cfg, err := bills.LoadConfig(serveConfigFile)
+	if err != nil {
+		return err
+	}
+	flags := cmd.Flags()
+
+	if !flags.Changed("repo") && !flags.Changed("org") && !flags.Changed("repos") {
+		setString(flags, "repo", &serveRepo, cfg.Repository)
+		setString(flags, "org", &serveOrg, cfg.Organization)
+		setSlice(flags, "repos", &serveRepos, cfg.Repositories)
+	}
+	if !flags.Changed("pricing") {
+		serveConfigPricing = cfg.Pricing
+	}
+	setFilter(flags, &serveFilter, cfg.Filter)
+
+	setValue(flags, "concurrency", &serveConcurrency, cfg.Concurrency)
+	setValue(flags, "collect-errors", &serveCollectErrors, cfg.CollectErrors)
+	setValue(flags, "tolerant", &serveTolerant, cfg.Tolerant)
+
+	serveClient.applyConfig(flags, cfg)
+	return nil
+}
+
+// setFilter sets the filter flags not given on the command line to the keys under filter of the configuration file
+func setFilter(flags *pflag.FlagSet, filter *bills.WorkflowFilter, cfg bills.WorkflowFilter) {
+	setSlice(flags, "include-name", &filter.IncludeNames, cfg.IncludeNames)
+	setSlice(flags, "exclude-name", &filter.ExcludeNames, cfg.ExcludeNames)
+	setSlice(flags, "include-path", &filter.IncludePaths, cfg.IncludePaths)
+	setSlice(flags, "exclude-path", &filter.ExcludePaths, cfg.ExcludePaths)
+	setSlice(flags, "include-state", &filter.IncludeStates, cfg.IncludeStates)
+	setSlice(flags, "exclude-state", &filter.ExcludeStates, cfg.ExcludeStates)
+}
+
+// applyConfig sets the client flags not given on the command line to the values of the configuration file
+func (f *clientFlags) applyConfig(flags *pflag.FlagSet, cfg bills.Config) {
+	setValue(flags, "max-retries", &f.maxRetries, cfg.MaxRetries)
+	setValue(flags, "max-rate-limit-wait", &f.maxRateLimitWait, cfg.MaxRateLimitWait)
+
+	setString(flags, "api-url", &f.apiURL, cfg.APIURL)
+	setString(flags, "upload-url", &f.uploadURL, cfg.UploadURL)
+
+	setValue(flags, "app-id", &f.appID, cfg.AppID)
+	setString(flags, "app-private-key-file", &f.appPrivateKeyFile, cfg.AppPrivateKeyFile)
+}
+
+// setString sets the variable of the flag to the value of the configuration file, unless the flag is given or the key is not set
+func setString(flags *pflag.FlagSet, name string, p *string, value string) {
+	if value != "" && !flags.Changed(name) {
+		*p = value
+	}
+}
+
+// setSlice sets the variable of the flag to the value of the configuration file, unless the flag is given or the key is not set
+func setSlice(flags *pflag.FlagSet, name string, p *[]string, value []string) {
+	if value != nil && !flags.Changed(name) {
+		*p = value
+	}
+}
+
+// setValue sets the variable of the flag to the value of the configuration file, unless the flag is given or the key is not set
+func setValue[T any](flags *pflag.FlagSet, name string, p *T, value *T) {
+	if value != nil && !flags.Changed(name) {
+		*p = *value
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func Test_config_subcommands(t *testing.T) {
+	dir := t.TempDir()
+	config := `repository: owner/repo
+format: csv
+output: report.csv
+concurrency: 3
+tolerant: true
+max_retries: 1
+filter:
+  exclude_names: [zzz_*]
+`
+	if err := os.WriteFile(filepath.Join(dir, ".actbills.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	tests := []struct {
+		name  string
+		cmd   *cobra.Command
+		args  []string
+		check func(t *testing.T)
+	}{
+		{
+			name: "root",
+			cmd:  rootCmd,
+			args: []string{"--concurrency", "5"},
+			check: func(t *testing.T) {
+				if repo != "owner/repo" || format != "csv" || output != "report.csv" {
+					t.Errorf("repo, format, output = %v, %v, %v, want owner/repo, csv, report.csv", repo, format, output)
+				}
+				if concurrency != 5 {
+					t.Errorf("concurrency = %v, want the flag 5", concurrency)
+				}
+			},
+		},
+		{
+			name: "serve",
+			cmd:  serveCmd,
+			args: []string{"serve", "--max-retries", "2"},
+			check: func(t *testing.T) {
+				if serveRepo != "owner/repo" || serveConcurrency != 3 || !serveTolerant {
+					t.Errorf("repo, concurrency, tolerant = %v, %v, %v, want owner/repo, 3, true", serveRepo, serveConcurrency, serveTolerant)
+				}
+				if len(serveFilter.ExcludeNames) != 1 || serveFilter.ExcludeNames[0] != "zzz_*" {
+					t.Errorf("exclude names = %v, want [zzz_*]", serveFilter.ExcludeNames)
+				}
+				if serveClient.maxRetries != 2 {
+					t.Errorf("max retries = %v, want the flag 2", serveClient.maxRetries)
+				}
+			},
+		},
+		{
+			name: "summary",
+			cmd:  summaryCmd,
+			args: []string{"summary", "--user", "octocat"},
+			check: func(t *testing.T) {
+				if summaryFormat != "markdown" || summaryOutput != "" {
+					t.Errorf("format, output = %v, %v, want markdown and no output", summaryFormat, summaryOutput)
+				}
+				if summaryClient.maxRetries == 1 {
+					t.Errorf("max retries = %v, want the default", summaryClient.maxRetries)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the command is run without sending requests, keeping the values of the flags to check
+			run := tt.cmd.Run
+			tt.cmd.Run = func(*cobra.Command, []string) {}
+			t.Cleanup(func() { tt.cmd.Run = run })
+
+			rootCmd.SetArgs(tt.args)
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			tt.check(t)
+		})
+	}
+}
@@ -22,15 +22,66 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/koh-sh/actbills/internal/bills"
 	"github.com/spf13/cobra"
 )
 
-var repo string
+var (
+	repo         string
+	org          string
+	repos        []string
+	pricingFile  string
+	format       string
+	output       string
+	jsonReport   string
+	templateFile string
+	topJobs      int
+
+	sortKey   string
+	sortOrder string
+	top       int
+
+	filter bills.WorkflowFilter
+
+	largerRunners bool
+	runnerSKUs    map[string]string
+	storage       bool
+
+	budgetFile   string
+	failOnBudget bool
+
+	concurrency   int
+	collectErrors bool
+	tolerant      bool
+	failOnPartial bool
+
+	client clientFlags
+
+	snapshotFile string
+	snapshotKeep int
+
+	billingCycleDay int
+	forecast        bool
+	plan            string
+
+	billingSummary bool
+
+	commentOn     int
+	trackingIssue bool
+	publishRepo   string
+
+	slackWebhookURL string
+	webhookURL      string
+	notifyDryRun    bool
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -41,10 +92,69 @@ var rootCmd = &cobra.Command{
 This CLI tool retrieves GitHub repository Workflows and their billable times.
 It aggregates the time spent by runner OS (Ubuntu, Windows, macOS).
 Results are output in a markdown table for easy review and management.`,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if err := applyConfig(cmd); err != nil {
+			log.Fatal(err)
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		err := bills.CreateReport(repo)
+		err := bills.CreateReport(cmd.Context(), bills.Options{
+			Repository:   repo,
+			Organization: org,
+			Repositories: repos,
+			PricingFile:  pricingFile,
+			Pricing:      configPricing,
+			Format:       bills.Format(format),
+			OutputPath:   output,
+			JSONReport:   jsonReport,
+			TemplateFile: templateFile,
+			TopJobs:      topJobs,
+
+			Sort:      bills.SortKey(sortKey),
+			SortOrder: bills.SortOrder(sortOrder),
+			Top:       top,
+
+			Filter: filter,
+
+			LargerRunners: largerRunners,
+			RunnerSKUs:    runnerSKUs,
+			Storage:       storage,
+
+			BudgetFile:   budgetFile,
+			Budget:       configBudget,
+			FailOnBudget: failOnBudget,
+
+			Concurrency:   concurrency,
+			CollectErrors: collectErrors,
+			Tolerant:      tolerant,
+			FailOnPartial: failOnPartial,
+
+			MaxRetries:       client.maxRetries,
+			MaxRateLimitWait: client.maxRateLimitWait,
+
+			APIURL:    client.apiURL,
+			UploadURL: client.uploadURL,
+
+			AppID:             client.appID,
+			AppPrivateKeyFile: client.appPrivateKeyFile,
+
+			SnapshotFile: snapshotFile,
+			SnapshotKeep: snapshotKeep,
+
+			BillingCycleDay: billingCycleDay,
+			Forecast:        forecast,
+			Plan:            plan,
+
+			BillingSummary: billingSummary,
+
+			CommentOn:         commentOn,
+			TrackingIssue:     trackingIssue,
+			PublishRepository: publishRepo,
+
+			SlackWebhookURL: slackWebhookURL,
+			WebhookURL:      webhookURL,
+			NotifyDryRun:    notifyDryRun,
+		})
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -53,24 +163,87 @@ Results are output in a markdown table for easy review and management.`,
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The context of the commands is cancelled on an interrupt signal.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
-	// Here you will define your flags and configuration settings.
-	// Cobra supports persistent flags, which, if defined here,
-	// will be global for your application.
+	rootCmd.Flags().StringVar(&configFile, "config", "", "Path to the YAML configuration file (default "+bills.DefaultConfigFile+" if it exists)")
+	rootCmd.Flags().StringVar(&repo, "repo", "", "GitHub Repository name (default $GITHUB_REPOSITORY)")
+	rootCmd.Flags().StringVar(&org, "org", "", "GitHub Organization name. Reports all private repositories of the organization")
+	rootCmd.Flags().StringSliceVar(&repos, "repos", nil, "Repositories in owner/repo format to report together (e.g. owner/app,owner/lib)")
+	rootCmd.MarkFlagsMutuallyExclusive("repo", "org", "repos")
+	rootCmd.Flags().StringVar(&format, "format", string(bills.FormatMarkdown), fmt.Sprintf("Output format %v", bills.Formats))
+	rootCmd.Flags().StringVarP(&output, "output", "o", "", "Path to write the report to (default $GITHUB_STEP_SUMMARY for markdown, or stdout)")
+	rootCmd.Flags().StringVar(&jsonReport, "json-report", "", "Path to write the JSON report to in addition to the report in the output format")
+	rootCmd.Flags().StringVar(&templateFile, "template", "", "Path to a Go text/template file rendering the markdown report (default built-in template)")
+	rootCmd.Flags().StringVar(&sortKey, "sort", string(bills.SortByName), fmt.Sprintf("Key to sort the workflow tables by %v", bills.SortKeys))
+	rootCmd.Flags().StringVar(&sortOrder, "sort-order", "", "Order to sort the workflow tables in (asc or desc) (default asc by name, desc by the other keys)")
+	rootCmd.Flags().IntVar(&top, "top", 0, "Number of workflows listed in each workflow table, with the rest collapsed into an \"Other\" row (0 lists all workflows)")
+	addFilterFlags(rootCmd, &filter)
+	rootCmd.Flags().IntVar(&topJobs, "top-jobs", 0, "Number of jobs to list by billable time in this billing cycle (0 disables the job breakdown)")
+	rootCmd.Flags().BoolVar(&largerRunners, "larger-runners", false, "Aggregate the billable time of the jobs run on larger runners in this billing cycle")
+	rootCmd.Flags().StringToStringVar(&runnerSKUs, "runner-sku", nil, "Map a runner label or runner group name to a larger runner SKU (e.g. big-runner=linux-8-core)")
+	rootCmd.Flags().BoolVar(&storage, "storage", false, "List the artifacts and the cache usage of each repository with the estimated storage cost")
+	rootCmd.Flags().StringVar(&pricingFile, "pricing", "", "Path to a JSON file overriding the default minute multipliers and prices")
+	rootCmd.Flags().StringVar(&budgetFile, "budget", "", "Path to a JSON file with the budget thresholds")
+	rootCmd.Flags().BoolVar(&failOnBudget, "fail-on-budget", false, "Exit with a non-zero status when an error threshold of the budget is crossed")
+	rootCmd.Flags().IntVar(&concurrency, "concurrency", bills.DefaultConcurrency, "Maximum number of requests sent to the GitHub API concurrently")
+	rootCmd.Flags().BoolVar(&collectErrors, "collect-errors", false, "Report all failed requests instead of stopping at the first error")
+	rootCmd.Flags().BoolVar(&tolerant, "tolerant", false, "Render the report without the workflows that could not be fetched and list them with the reason")
+	rootCmd.Flags().BoolVar(&failOnPartial, "fail-on-partial", false, "Exit with a non-zero status when some workflows could not be fetched with --tolerant")
+	client.add(rootCmd)
+	rootCmd.Flags().StringVar(&snapshotFile, "snapshot", "", "Path to a JSON file keeping the snapshots of the previous runs to show the change since the previous run")
+	rootCmd.Flags().IntVar(&snapshotKeep, "snapshot-keep", bills.DefaultSnapshotKeep, "Number of snapshots kept in the snapshot file (0 keeps all snapshots)")
+	rootCmd.Flags().IntVar(&billingCycleDay, "billing-cycle-day", 1, "Day of the month the billing cycle starts on (1-28)")
+	rootCmd.Flags().BoolVar(&forecast, "forecast", false, "Project the billable time to the end of the billing cycle")
+	rootCmd.Flags().StringVar(&plan, "plan", "", "GitHub plan to compare the forecast with the included minutes (free, pro, team or enterprise)")
+	rootCmd.Flags().BoolVar(&billingSummary, "billing-summary", false, "Render the Actions billing summary of the organization or the repository owner above the workflow tables")
+	rootCmd.Flags().IntVar(&commentOn, "comment-on", 0, "Number of the issue or pull request to post the markdown report to as a comment, updated on each run")
+	rootCmd.Flags().BoolVar(&trackingIssue, "tracking-issue", false, "Open a tracking issue with the markdown report for each billing cycle, updated on each run")
+	rootCmd.Flags().StringVar(&publishRepo, "publish-repo", "", "Repository to post the comment and the tracking issue to (default the repository of the report, or $GITHUB_REPOSITORY with --org)")
+	rootCmd.Flags().StringVar(&slackWebhookURL, "slack-webhook-url", "", "Slack incoming webhook URL to post the summary of the report to")
+	rootCmd.Flags().StringVar(&webhookURL, "webhook-url", "", "Webhook URL to post the JSON report to")
+	rootCmd.Flags().BoolVar(&notifyDryRun, "notify-dry-run", false, "Print the payloads of the notifications to stderr instead of sending them")
+}
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.actbills.yaml)")
+// clientFlags represents the flags configuring the GitHub API client, bound separately for each command
+type clientFlags struct {
+	maxRetries       int
+	maxRateLimitWait time.Duration
 
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	rootCmd.Flags().StringVar(&repo, "repo", "", "GitHub Repository name (default $GITHUB_REPOSITORY)")
+	apiURL    string
+	uploadURL string
+
+	appID             int64
+	appPrivateKeyFile string
+}
+
+// add adds the flags configuring the GitHub API client to the command
+func (f *clientFlags) add(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.maxRetries, "max-retries", bills.DefaultMaxRetries, "Maximum number of retries of a request failed by the rate limits or server errors (0 disables retries)")
+	cmd.Flags().DurationVar(&f.maxRateLimitWait, "max-rate-limit-wait", bills.DefaultMaxRateLimitWait, "Maximum time to wait for a rate limit to reset before giving up")
+	cmd.Flags().StringVar(&f.apiURL, "api-url", "", "GitHub API URL, e.g. https://github.example.com/api/v3 for GitHub Enterprise Server (default $GITHUB_API_URL or https://api.github.com)")
+	cmd.Flags().StringVar(&f.uploadURL, "upload-url", "", "GitHub upload URL (default derived from the API URL)")
+	cmd.Flags().Int64Var(&f.appID, "app-id", 0, "GitHub App ID. Authenticates as the installation of the GitHub App on the owner instead of $GITHUB_TOKEN")
+	cmd.Flags().StringVar(&f.appPrivateKeyFile, "app-private-key-file", "", "Path to the PEM private key of the GitHub App (default $GITHUB_APP_PRIVATE_KEY)")
+}
+
+// addFilterFlags adds the flags filtering the workflows to the command, bound to the filter
+func addFilterFlags(cmd *cobra.Command, filter *bills.WorkflowFilter) {
+	cmd.Flags().StringSliceVar(&filter.IncludeNames, "include-name", nil, "Glob patterns of the workflow names to include (e.g. release-*)")
+	cmd.Flags().StringSliceVar(&filter.ExcludeNames, "exclude-name", nil, "Glob patterns of the workflow names to exclude")
+	cmd.Flags().StringSliceVar(&filter.IncludePaths, "include-path", nil, "Glob patterns of the workflow file paths to include (e.g. .github/workflows/release-*.yml)")
+	cmd.Flags().StringSliceVar(&filter.ExcludePaths, "exclude-path", nil, "Glob patterns of the workflow file paths to exclude (e.g. dynamic/*/* for Dependabot and CodeQL)")
+	cmd.Flags().StringSliceVar(&filter.IncludeStates, "include-state", nil, fmt.Sprintf("Workflow states to include %v", bills.WorkflowStates))
+	cmd.Flags().StringSliceVar(&filter.ExcludeStates, "exclude-state", nil, fmt.Sprintf("Workflow states to exclude %v", bills.WorkflowStates))
 }
 
 // set version from goreleaser variables
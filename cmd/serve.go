@@ -0,0 +1,107 @@
+/*
+Copyright © 2024 koh-sh
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/koh-sh/actbills/internal/bills"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveRepo        string
+	serveOrg         string
+	serveRepos       []string
+	servePricingFile string
+	serveAddr        string
+	serveInterval    time.Duration
+
+	serveFilter bills.WorkflowFilter
+
+	serveConcurrency   int
+	serveCollectErrors bool
+	serveTolerant      bool
+
+	serveClient clientFlags
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Expose the billable time of the workflows as OpenMetrics for Prometheus.",
+	Long: `Expose the billable time of the workflows as OpenMetrics for Prometheus.
+
+The billable time of the workflows of the repository or the organization is refreshed on an interval,
+and served on /metrics from the latest refresh, so scrapes do not send requests to the GitHub API.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if err := applyServeConfig(cmd); err != nil {
+			log.Fatal(err)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := bills.Serve(cmd.Context(), bills.Options{
+			Repository:   serveRepo,
+			Organization: serveOrg,
+			Repositories: serveRepos,
+			PricingFile:  servePricingFile,
+			Pricing:      serveConfigPricing,
+
+			Filter: serveFilter,
+
+			Concurrency:   serveConcurrency,
+			CollectErrors: serveCollectErrors,
+			Tolerant:      serveTolerant,
+
+			MaxRetries:       serveClient.maxRetries,
+			MaxRateLimitWait: serveClient.maxRateLimitWait,
+
+			APIURL:    serveClient.apiURL,
+			UploadURL: serveClient.uploadURL,
+
+			AppID:             serveClient.appID,
+			AppPrivateKeyFile: serveClient.appPrivateKeyFile,
+		}, serveAddr, serveInterval)
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveConfigFile, "config", "", "Path to the YAML configuration file (default "+bills.DefaultConfigFile+" if it exists)")
+	serveCmd.Flags().StringVar(&serveRepo, "repo", "", "GitHub Repository name (default $GITHUB_REPOSITORY)")
+	serveCmd.Flags().StringVar(&serveOrg, "org", "", "GitHub Organization name. Exposes all private repositories of the organization")
+	serveCmd.Flags().StringSliceVar(&serveRepos, "repos", nil, "Repositories in owner/repo format to expose together (e.g. owner/app,owner/lib)")
+	serveCmd.MarkFlagsMutuallyExclusive("repo", "org", "repos")
+	serveCmd.Flags().StringVar(&servePricingFile, "pricing", "", "Path to a JSON file overriding the default minute multipliers and prices")
+	serveCmd.Flags().StringVar(&serveAddr, "listen", ":9090", "Address to serve the metrics on")
+	serveCmd.Flags().DurationVar(&serveInterval, "interval", bills.DefaultServeInterval, "Interval of refreshing the billable time from the GitHub API (at least 1m)")
+	addFilterFlags(serveCmd, &serveFilter)
+	serveCmd.Flags().IntVar(&serveConcurrency, "concurrency", bills.DefaultConcurrency, "Maximum number of requests sent to the GitHub API concurrently")
+	serveCmd.Flags().BoolVar(&serveCollectErrors, "collect-errors", false, "Report all failed requests instead of stopping at the first error")
+	serveCmd.Flags().BoolVar(&serveTolerant, "tolerant", false, "Expose the metrics without the workflows that could not be fetched")
+	serveClient.add(serveCmd)
+}
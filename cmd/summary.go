@@ -0,0 +1,87 @@
+/*
+Copyright © 2024 koh-sh
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/koh-sh/actbills/internal/bills"
+	"github.com/spf13/cobra"
+)
+
+var (
+	summaryUser       string
+	summaryOrg        string
+	summaryEnterprise string
+	summaryFormat     string
+	summaryOutput     string
+	summaryClient     clientFlags
+)
+
+// summaryCmd represents the summary command
+var summaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Report the Actions billing summary of a user, an organization or an enterprise.",
+	Long: `Report the Actions billing summary of a user, an organization or an enterprise.
+
+The summary is fetched from the account-level billing API, and includes the total minutes used,
+the paid minutes, the included minutes of the plan and the minutes used for each runner.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		account := bills.BillingAccount{Type: bills.AccountUser, Name: summaryUser}
+		switch {
+		case summaryOrg != "":
+			account = bills.BillingAccount{Type: bills.AccountOrganization, Name: summaryOrg}
+		case summaryEnterprise != "":
+			account = bills.BillingAccount{Type: bills.AccountEnterprise, Name: summaryEnterprise}
+		}
+
+		err := bills.CreateSummary(cmd.Context(), bills.Options{
+			Format:     bills.Format(summaryFormat),
+			OutputPath: summaryOutput,
+
+			MaxRetries:       summaryClient.maxRetries,
+			MaxRateLimitWait: summaryClient.maxRateLimitWait,
+
+			APIURL:    summaryClient.apiURL,
+			UploadURL: summaryClient.uploadURL,
+
+			AppID:             summaryClient.appID,
+			AppPrivateKeyFile: summaryClient.appPrivateKeyFile,
+		}, account)
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(summaryCmd)
+
+	summaryCmd.Flags().StringVar(&summaryUser, "user", "", "GitHub user login")
+	summaryCmd.Flags().StringVar(&summaryOrg, "org", "", "GitHub Organization name")
+	summaryCmd.Flags().StringVar(&summaryEnterprise, "enterprise", "", "GitHub Enterprise slug")
+	summaryCmd.MarkFlagsMutuallyExclusive("user", "org", "enterprise")
+	summaryCmd.MarkFlagsOneRequired("user", "org", "enterprise")
+	summaryCmd.Flags().StringVar(&summaryFormat, "format", string(bills.FormatMarkdown), "Output format (markdown or json)")
+	summaryCmd.Flags().StringVarP(&summaryOutput, "output", "o", "", "Path to write the summary to (default $GITHUB_STEP_SUMMARY for markdown, or stdout)")
+	summaryClient.add(summaryCmd)
+}
@@ -6,6 +6,8 @@ require (
 	github.com/google/go-github/v60 v60.0.0
 	github.com/migueleliasweb/go-github-mock v1.0.0
 	github.com/spf13/cobra v1.8.1
+	github.com/spf13/pflag v1.0.5
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -13,6 +15,5 @@ require (
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/gorilla/mux v1.8.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/time v0.3.0 // indirect
 )
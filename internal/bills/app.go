@@ -0,0 +1,186 @@
+package bills
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+const (
+	// appJWTLifetime is the lifetime of a JWT of a GitHub App. GitHub allows 10 minutes at most.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew is subtracted from the issued time of a JWT to allow the clock skew with GitHub
+	appJWTClockSkew = time.Minute
+	// installationTokenRefreshMargin is the time before the expiry of an installation access token to refresh it
+	installationTokenRefreshMargin = 5 * time.Minute
+)
+
+// loadAppPrivateKey loads the private key of a GitHub App from the PEM file specified by the filePath.
+// If the filePath is empty, the key is read from the GITHUB_APP_PRIVATE_KEY environment variable.
+func loadAppPrivateKey(filePath string) (*rsa.PrivateKey, error) {
+	data := []byte(os.Getenv("GITHUB_APP_PRIVATE_KEY"))
+	source := "GITHUB_APP_PRIVATE_KEY"
+	if filePath != "" {
+		var err error
+		data, err = os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file %s: %w", filePath, err)
+		}
+		source = filePath
+	}
+	if len(data) == 0 {
+		return nil, errors.New("private key of the GitHub App not provided and GITHUB_APP_PRIVATE_KEY environment variable not set")
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key in %s: no PEM data found", source)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key in %s: %w", source, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse private key in %s: not an RSA key", source)
+	}
+	return key, nil
+}
+
+// generateAppJWT generates a JWT signed with RS256 to authenticate as the GitHub App
+func generateAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// appJWTTransport is an http.RoundTripper authenticating the requests as the GitHub App with a new JWT
+type appJWTTransport struct {
+	base  http.RoundTripper
+	appID int64
+	key   *rsa.PrivateKey
+	now   func() time.Time
+}
+
+// RoundTrip sends the request with a JWT of the GitHub App
+func (t *appJWTTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := generateAppJWT(t.appID, t.key, t.now())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return t.base.RoundTrip(req)
+}
+
+// installationTransport is an http.RoundTripper authenticating the requests with an installation access token of the GitHub App.
+// The installation is looked up by the owner on the first request, and the token is refreshed before it expires.
+type installationTransport struct {
+	base  http.RoundTripper
+	apps  *github.Client // Client authenticated as the GitHub App
+	owner string         // Organization or user the GitHub App is installed on
+	now   func() time.Time
+
+	mu             sync.Mutex
+	installationID int64
+	token          string
+	expiresAt      time.Time
+}
+
+// newInstallationTransport creates an installationTransport for the installation of the GitHub App on the owner.
+// The base transport is used for both the requests authenticated as the GitHub App and as the installation.
+func newInstallationTransport(base http.RoundTripper, appID int64, key *rsa.PrivateKey, owner string) *installationTransport {
+	jwt := &appJWTTransport{base: base, appID: appID, key: key, now: time.Now}
+	return &installationTransport{
+		base:  base,
+		apps:  github.NewClient(&http.Client{Transport: jwt}),
+		owner: owner,
+		now:   time.Now,
+	}
+}
+
+// RoundTrip sends the request with an installation access token
+func (t *installationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.installationToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// installationToken returns the installation access token, creating a new one if it is not created yet or expires soon
+func (t *installationTransport) installationToken(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && t.now().Add(installationTokenRefreshMargin).Before(t.expiresAt) {
+		return t.token, nil
+	}
+
+	if t.installationID == 0 {
+		id, err := t.findInstallation(ctx)
+		if err != nil {
+			return "", err
+		}
+		t.installationID = id
+	}
+
+	token, _, err := t.apps.Apps.CreateInstallationToken(ctx, t.installationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create installation access token of installation %d: %w", t.installationID, err)
+	}
+	t.token = token.GetToken()
+	t.expiresAt = token.GetExpiresAt().Time
+
+	return t.token, nil
+}
+
+// findInstallation returns the ID of the installation of the GitHub App on the organization or the user
+func (t *installationTransport) findInstallation(ctx context.Context) (int64, error) {
+	installation, resp, err := t.apps.Apps.FindOrganizationInstallation(ctx, t.owner)
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		installation, _, err = t.apps.Apps.FindUserInstallation(ctx, t.owner)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find installation of the GitHub App on %s: %w", t.owner, err)
+	}
+	return installation.GetID(), nil
+}
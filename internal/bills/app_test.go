@@ -0,0 +1,225 @@
+package bills
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// newTestAppKey generates a private key of a GitHub App and writes it to a PKCS #1 PEM file
+func newTestAppKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "app.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+	return key, path
+}
+
+// verifyAppJWT verifies the signature of the JWT and returns its claims
+func verifyAppJWT(jwt string, key *rsa.PublicKey) (map[string]any, error) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT: %s", jwt)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+		return nil, err
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func Test_loadAppPrivateKey(t *testing.T) {
+	key, pkcs1File := newTestAppKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	pkcs8PEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+
+	tests := []struct {
+		name     string
+		filePath string
+		env      string
+		wantErr  bool
+	}{
+		{name: "pkcs1 file", filePath: pkcs1File, env: "", wantErr: false},
+		{name: "pkcs8 env", filePath: "", env: pkcs8PEM, wantErr: false},
+		{name: "file over env", filePath: pkcs1File, env: "invalid", wantErr: false},
+		{name: "not provided", filePath: "", env: "", wantErr: true},
+		{name: "file not found", filePath: "not_found.pem", env: "", wantErr: true},
+		{name: "not PEM", filePath: "", env: "invalid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_APP_PRIVATE_KEY", tt.env)
+			got, err := loadAppPrivateKey(tt.filePath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadAppPrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(key) {
+				t.Errorf("loadAppPrivateKey() returned a different key")
+			}
+		})
+	}
+}
+
+func Test_generateAppJWT(t *testing.T) {
+	key, _ := newTestAppKey(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	jwt, err := generateAppJWT(12345, key, now)
+	if err != nil {
+		t.Fatalf("generateAppJWT() error = %v", err)
+	}
+	claims, err := verifyAppJWT(jwt, &key.PublicKey)
+	if err != nil {
+		t.Fatalf("generateAppJWT() = %v, failed to verify: %v", jwt, err)
+	}
+	want := map[string]any{
+		"iat": float64(now.Add(-time.Minute).Unix()),
+		"exp": float64(now.Add(9 * time.Minute).Unix()),
+		"iss": "12345",
+	}
+	for name, value := range want {
+		if claims[name] != value {
+			t.Errorf("generateAppJWT() claim %s = %v, want %v", name, claims[name], value)
+		}
+	}
+}
+
+// newFakeAppServer creates a test server of the GitHub API for a GitHub App installed on the installations by owner.
+// Installations of organizations are keyed by "orgs/<owner>" and of users by "users/<owner>".
+// Each access token created is named token-<n>, and the tokens used for the workflows of the repositories are appended to used.
+func newFakeAppServer(t *testing.T, key *rsa.PublicKey, installations map[string]int64, used *[]string) *httptest.Server {
+	t.Helper()
+	created := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/installation"):
+			if _, err := verifyAppJWT(authorization, key); err != nil {
+				http.Error(w, `{"message": "A JSON web token could not be decoded"}`, http.StatusUnauthorized)
+				return
+			}
+			id, ok := installations[strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/installation")]
+			if !ok {
+				http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, `{"id": %d}`, id)
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/app/installations/"):
+			if _, err := verifyAppJWT(authorization, key); err != nil {
+				http.Error(w, `{"message": "A JSON web token could not be decoded"}`, http.StatusUnauthorized)
+				return
+			}
+			created++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "token-%d", "expires_at": %q}`, created, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+		case strings.HasSuffix(r.URL.Path, "/actions/workflows"):
+			*used = append(*used, authorization)
+			fmt.Fprint(w, `{"total_count": 0, "workflows": []}`)
+		default:
+			http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_installationTransport(t *testing.T) {
+	key, _ := newTestAppKey(t)
+	installations := map[string]int64{"orgs/org": 1, "users/user": 2}
+
+	tests := []struct {
+		name     string
+		owner    string
+		advances []time.Duration // time advanced before each request
+		want     []string        // tokens used for each request
+		wantErr  bool
+	}{
+		{
+			name:     "organization",
+			owner:    "org",
+			advances: []time.Duration{0, 10 * time.Minute},
+			want:     []string{"token-1", "token-1"},
+		},
+		{
+			name:     "user",
+			owner:    "user",
+			advances: []time.Duration{0},
+			want:     []string{"token-1"},
+		},
+		{
+			name:     "refresh before expiry",
+			owner:    "org",
+			advances: []time.Duration{0, 50 * time.Minute, 10 * time.Minute},
+			want:     []string{"token-1", "token-1", "token-2"},
+		},
+		{
+			name:     "not installed",
+			owner:    "other",
+			advances: []time.Duration{0},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var used []string
+			server := newFakeAppServer(t, &key.PublicKey, installations, &used)
+			baseURL, _ := url.Parse(server.URL + "/")
+
+			now := time.Now()
+			transport := newInstallationTransport(http.DefaultTransport, 1, key, tt.owner)
+			transport.apps.BaseURL = baseURL
+			transport.now = func() time.Time { return now }
+			client := github.NewClient(&http.Client{Transport: transport})
+			client.BaseURL = baseURL
+
+			for _, advance := range tt.advances {
+				now = now.Add(advance)
+				_, err := fetchWorkflows(context.Background(), client, tt.owner, "repo")
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("fetchWorkflows() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			if strings.Join(used, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("installationTransport used tokens %v, want %v", used, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package bills
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// AccountType represents the type of an account billed for GitHub Actions
+type AccountType string
+
+const (
+	AccountUser         AccountType = "user"         // Personal account
+	AccountOrganization AccountType = "organization" // Organization account
+	AccountEnterprise   AccountType = "enterprise"   // Enterprise account
+)
+
+// BillingAccount represents an account billed for GitHub Actions
+type BillingAccount struct {
+	Type AccountType // Account type
+	Name string      // User login, organization login or enterprise slug
+}
+
+// BillingSummary represents the Actions usage of an account in the current billing cycle, as reported by the billing API
+type BillingSummary struct {
+	Account              BillingAccount
+	TotalMinutesUsed     float64        // Total minutes used, with the minute multipliers applied
+	TotalPaidMinutesUsed float64        // Minutes used beyond the included minutes
+	IncludedMinutes      float64        // Minutes included in the plan
+	MinutesUsedBreakdown map[string]int // Minutes used for each runner (e.g. UBUNTU, MACOS_12_CORE)
+}
+
+// validate returns an error if the account type is not supported or the name is empty
+func (a BillingAccount) validate() error {
+	switch a.Type {
+	case AccountUser, AccountOrganization, AccountEnterprise:
+	default:
+		return fmt.Errorf("unsupported account type: %s", a.Type)
+	}
+	if a.Name == "" {
+		return fmt.Errorf("%s name not provided", a.Type)
+	}
+	return nil
+}
+
+// fetchBillingSummary retrieves the Actions billing summary of the account.
+// go-github has no method for the enterprise endpoint, so it is requested directly.
+func fetchBillingSummary(ctx context.Context, client *github.Client, account BillingAccount) (BillingSummary, error) {
+	var billing *github.ActionBilling
+	var err error
+	switch account.Type {
+	case AccountUser:
+		billing, _, err = client.Billing.GetActionsBillingUser(ctx, account.Name)
+	case AccountOrganization:
+		billing, _, err = client.Billing.GetActionsBillingOrg(ctx, account.Name)
+	case AccountEnterprise:
+		var req *http.Request
+		req, err = client.NewRequest(http.MethodGet, fmt.Sprintf("enterprises/%s/settings/billing/actions", account.Name), nil)
+		if err != nil {
+			return BillingSummary{}, err
+		}
+		billing = new(github.ActionBilling)
+		_, err = client.Do(ctx, req, billing)
+	default:
+		return BillingSummary{}, fmt.Errorf("unsupported account type: %s", account.Type)
+	}
+	if err != nil {
+		return BillingSummary{}, fmt.Errorf("failed to fetch Actions billing of %s %s: %w", account.Type, account.Name, err)
+	}
+
+	return BillingSummary{
+		Account:              account,
+		TotalMinutesUsed:     billing.TotalMinutesUsed,
+		TotalPaidMinutesUsed: billing.TotalPaidMinutesUsed,
+		IncludedMinutes:      billing.IncludedMinutes,
+		MinutesUsedBreakdown: billing.MinutesUsedBreakdown,
+	}, nil
+}
+
+// fetchOwnerBillingSummary retrieves the Actions billing summary of the owner of the repositories.
+// The owner is tried as an organization first, and as a user if it is not an organization.
+func fetchOwnerBillingSummary(ctx context.Context, client *github.Client, owner string) (BillingSummary, error) {
+	_, resp, err := client.Organizations.Get(ctx, owner)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return BillingSummary{}, fmt.Errorf("failed to fetch owner %s: %w", owner, err)
+	}
+	if err != nil {
+		return fetchBillingSummary(ctx, client, BillingAccount{Type: AccountUser, Name: owner})
+	}
+	return fetchBillingSummary(ctx, client, BillingAccount{Type: AccountOrganization, Name: owner})
+}
+
+// remainingMinutes returns the included minutes not used yet
+func (s BillingSummary) remainingMinutes() float64 {
+	return max(s.IncludedMinutes-(s.TotalMinutesUsed-s.TotalPaidMinutesUsed), 0)
+}
+
+// runners returns the runners of the breakdown sorted by name
+func (s BillingSummary) runners() []string {
+	runners := make([]string, 0, len(s.MinutesUsedBreakdown))
+	for runner := range s.MinutesUsedBreakdown {
+		runners = append(runners, runner)
+	}
+	sort.Strings(runners)
+	return runners
+}
+
+// generateMarkdown generates a markdown section with the billing summary and the breakdown of the minutes used for each runner
+func (s BillingSummary) generateMarkdown(heading string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s Actions billing summary\n\n", heading))
+	sb.WriteString(fmt.Sprintf("Account: %s (%s)\n\n", s.Account.Name, s.Account.Type))
+	sb.WriteString("| Total (min) | Paid (min) | Included (min) | Remaining (min) |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+		formatMinutes(s.TotalMinutesUsed), formatMinutes(s.TotalPaidMinutesUsed), formatMinutes(s.IncludedMinutes), formatMinutes(s.remainingMinutes())))
+
+	if len(s.MinutesUsedBreakdown) > 0 {
+		sb.WriteString("\n| Runner | Used (min) |\n")
+		sb.WriteString("| --- | --- |\n")
+		for _, runner := range s.runners() {
+			sb.WriteString(fmt.Sprintf("| %s | %d |\n", runner, s.MinutesUsedBreakdown[runner]))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// jsonBillingSummary represents the Actions billing summary of an account
+type jsonBillingSummary struct {
+	Account              string         `json:"account"`
+	AccountType          AccountType    `json:"account_type"`
+	TotalMinutesUsed     float64        `json:"total_minutes_used"`
+	TotalPaidMinutesUsed float64        `json:"total_paid_minutes_used"`
+	IncludedMinutes      float64        `json:"included_minutes"`
+	MinutesUsedBreakdown map[string]int `json:"minutes_used_breakdown"`
+}
+
+// jsonBillingSummaryReport represents the JSON document of the summary subcommand
+type jsonBillingSummaryReport struct {
+	Version     int       `json:"version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	jsonBillingSummary
+}
+
+// newJSONBillingSummary converts a BillingSummary to a jsonBillingSummary
+func newJSONBillingSummary(s BillingSummary) jsonBillingSummary {
+	breakdown := s.MinutesUsedBreakdown
+	if breakdown == nil {
+		breakdown = map[string]int{}
+	}
+	return jsonBillingSummary{
+		Account:              s.Account.Name,
+		AccountType:          s.Account.Type,
+		TotalMinutesUsed:     s.TotalMinutesUsed,
+		TotalPaidMinutesUsed: s.TotalPaidMinutesUsed,
+		IncludedMinutes:      s.IncludedMinutes,
+		MinutesUsedBreakdown: breakdown,
+	}
+}
+
+// render renders the billing summary in the specified format.
+// The CSV format is not supported as the summary is not a list of workflows.
+func (s BillingSummary) render(format Format, generatedAt time.Time) (string, error) {
+	switch format {
+	case FormatMarkdown:
+		return s.generateMarkdown("#"), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(jsonBillingSummaryReport{
+			Version:            jsonReportVersion,
+			GeneratedAt:        generatedAt,
+			jsonBillingSummary: newJSONBillingSummary(s),
+		}, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", fmt.Errorf("unsupported format for the billing summary: %s", format)
+	}
+}
+
+// CreateSummary fetches the Actions billing summary of the account and writes it to the output.
+// The client options, Format and OutputPath of opts are used.
+func CreateSummary(ctx context.Context, opts Options, account BillingAccount) error {
+	if opts.Format == "" {
+		opts.Format = FormatMarkdown
+	}
+	if err := opts.Format.validate(); err != nil {
+		return err
+	}
+	if err := account.validate(); err != nil {
+		return err
+	}
+
+	// look up the installation of the GitHub App on the account
+	opts.Organization = account.Name
+	client, err := createGitHubClient(opts)
+	if err != nil {
+		return err
+	}
+
+	summary, err := fetchBillingSummary(ctx, client, account)
+	if err != nil {
+		return err
+	}
+	content, err := summary.render(opts.Format, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(opts.OutputPath, opts.Format, content)
+}
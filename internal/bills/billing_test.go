@@ -0,0 +1,210 @@
+package bills
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// newBillingTestClient creates a client of a test server responding with the bodies by path, and 404 for the other paths
+func newBillingTestClient(t *testing.T, bodies map[string]string) *github.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	return client
+}
+
+const billingTestBody = `{"total_minutes_used": 3100, "total_paid_minutes_used": 100, "included_minutes": 3000, "minutes_used_breakdown": {"UBUNTU": 2000, "MACOS": 1100}}`
+
+func Test_fetchBillingSummary(t *testing.T) {
+	client := newBillingTestClient(t, map[string]string{
+		"/users/user/settings/billing/actions":             billingTestBody,
+		"/orgs/org/settings/billing/actions":               billingTestBody,
+		"/enterprises/enterprise/settings/billing/actions": billingTestBody,
+	})
+	tests := []struct {
+		name    string
+		account BillingAccount
+		wantErr bool
+	}{
+		{name: "user", account: BillingAccount{Type: AccountUser, Name: "user"}},
+		{name: "organization", account: BillingAccount{Type: AccountOrganization, Name: "org"}},
+		{name: "enterprise", account: BillingAccount{Type: AccountEnterprise, Name: "enterprise"}},
+		{name: "not found", account: BillingAccount{Type: AccountOrganization, Name: "other"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetchBillingSummary(context.Background(), client, tt.account)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchBillingSummary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := BillingSummary{
+				Account:              tt.account,
+				TotalMinutesUsed:     3100,
+				TotalPaidMinutesUsed: 100,
+				IncludedMinutes:      3000,
+				MinutesUsedBreakdown: map[string]int{"UBUNTU": 2000, "MACOS": 1100},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("fetchBillingSummary() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_fetchOwnerBillingSummary(t *testing.T) {
+	client := newBillingTestClient(t, map[string]string{
+		"/orgs/org":                            `{"login": "org"}`,
+		"/orgs/org/settings/billing/actions":   billingTestBody,
+		"/users/user/settings/billing/actions": billingTestBody,
+	})
+	tests := []struct {
+		name  string
+		owner string
+		want  BillingAccount
+	}{
+		{name: "organization", owner: "org", want: BillingAccount{Type: AccountOrganization, Name: "org"}},
+		{name: "user", owner: "user", want: BillingAccount{Type: AccountUser, Name: "user"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetchOwnerBillingSummary(context.Background(), client, tt.owner)
+			if err != nil {
+				t.Fatalf("fetchOwnerBillingSummary() error = %v", err)
+			}
+			if got.Account != tt.want {
+				t.Errorf("fetchOwnerBillingSummary() account = %v, want %v", got.Account, tt.want)
+			}
+		})
+	}
+}
+
+func TestBillingSummary_render(t *testing.T) {
+	summary := BillingSummary{
+		Account:              BillingAccount{Type: AccountOrganization, Name: "org"},
+		TotalMinutesUsed:     2500.5,
+		IncludedMinutes:      3000,
+		MinutesUsedBreakdown: map[string]int{"UBUNTU": 2000, "MACOS": 500},
+	}
+	generatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		summary BillingSummary
+		format  Format
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "markdown",
+			summary: summary,
+			format:  FormatMarkdown,
+			want: `# Actions billing summary
+
+Account: org (organization)
+
+| Total (min) | Paid (min) | Included (min) | Remaining (min) |
+| --- | --- | --- | --- |
+| 2500.5 | 0 | 3000 | 499.5 |
+
+| Runner | Used (min) |
+| --- | --- |
+| MACOS | 500 |
+| UBUNTU | 2000 |
+
+`,
+		},
+		{
+			name: "markdown over the included minutes",
+			summary: BillingSummary{
+				Account:              BillingAccount{Type: AccountUser, Name: "user"},
+				TotalMinutesUsed:     2100,
+				TotalPaidMinutesUsed: 100,
+				IncludedMinutes:      2000,
+			},
+			format: FormatMarkdown,
+			want: `# Actions billing summary
+
+Account: user (user)
+
+| Total (min) | Paid (min) | Included (min) | Remaining (min) |
+| --- | --- | --- | --- |
+| 2100 | 100 | 2000 | 0 |
+
+`,
+		},
+		{
+			name:    "json",
+			summary: summary,
+			format:  FormatJSON,
+			want: `{
+  "version": 1,
+  "generated_at": "2024-05-01T12:00:00Z",
+  "account": "org",
+  "account_type": "organization",
+  "total_minutes_used": 2500.5,
+  "total_paid_minutes_used": 0,
+  "included_minutes": 3000,
+  "minutes_used_breakdown": {
+    "MACOS": 500,
+    "UBUNTU": 2000
+  }
+}
+`,
+		},
+		{
+			name:    "csv",
+			summary: summary,
+			format:  FormatCSV,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.summary.render(tt.format, generatedAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BillingSummary.render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BillingSummary.render() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBillingAccount_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		account BillingAccount
+		wantErr bool
+	}{
+		{name: "enterprise", account: BillingAccount{Type: AccountEnterprise, Name: "enterprise"}, wantErr: false},
+		{name: "unsupported type", account: BillingAccount{Type: "team", Name: "team"}, wantErr: true},
+		{name: "empty name", account: BillingAccount{Type: AccountUser}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.account.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("BillingAccount.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,9 +1,15 @@
 package bills
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/google/go-github/v60/github"
 )
@@ -14,121 +20,781 @@ const (
 
 - This list shows the execution time for each Workflow at the time this Action was executed.
 - Workflows that have been deleted at the time of execution will not be listed.
-- Execution times using Larger runners are not included in the aggregation.`
-	tableHeader    = "| Workflow | Ubuntu (min) | Windows (min) | Macos (min) |\n"
-	tableSeparator = "| --- | --- | --- | --- |\n"
+- Execution times using Larger runners are not included in the workflow table. They are aggregated from the jobs when the larger runner breakdown is enabled.
+- Weighted minutes apply the minute multiplier of each runner OS, and costs are estimated from the price per minute without deducting the included minutes.`
 )
 
-// WorkflowBillableTimes represents a map of workflow names to their corresponding WorkflowBillableTime
-type WorkflowBillableTimes map[string]WorkflowBillableTime
+var (
+	// billableTimeColumns are the columns of the billable time tables following the first column
+	billableTimeColumns = []string{"Ubuntu (min)", "Windows (min)", "Macos (min)", "Weighted (min)", "Cost (USD)"}
+	// deltaColumns are the columns of the change since the previous snapshot
+	deltaColumns = []string{"Ubuntu (+/-)", "Windows (+/-)", "Macos (+/-)"}
+)
+
+var (
+	// ErrBudgetExceeded is returned by CreateReport when an error threshold of the budget is crossed and Options.FailOnBudget is set
+	ErrBudgetExceeded = errors.New("budget exceeded")
+	// ErrPartialReport is returned by CreateReport when some workflows could not be fetched and Options.FailOnPartial is set
+	ErrPartialReport = errors.New("some workflows could not be fetched")
+)
+
+// Format represents the output format of a report
+type Format string
+
+const (
+	FormatMarkdown    Format = "markdown"    // Markdown table for the job summary
+	FormatJSON        Format = "json"        // Versioned JSON document for machine consumption
+	FormatCSV         Format = "csv"         // CSV with one row for each workflow
+	FormatOpenMetrics Format = "openmetrics" // OpenMetrics text exposition for Prometheus
+)
+
+// Formats is the list of supported output formats
+var Formats = []Format{FormatMarkdown, FormatJSON, FormatCSV, FormatOpenMetrics}
+
+// validate returns an error if the format is not supported
+func (f Format) validate() error {
+	for _, format := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported format: %s", f)
+}
+
+// Options represents the options for creating a report
+type Options struct {
+	Repository   string   // Repository name in owner/repo format (default $GITHUB_REPOSITORY)
+	Organization string   // Organization name. If set, all private repositories of the organization are reported
+	Repositories []string // Repositories in owner/repo format reported together, instead of Repository or Organization
+	PricingFile  string   // Path to a JSON file overriding the default pricing
+	Pricing      *Pricing // Pricing used instead of the one of PricingFile (e.g. from the configuration file)
+	Format       Format   // Output format (default markdown)
+	OutputPath   string   // Path to write the report to (default $GITHUB_STEP_SUMMARY for markdown, or stdout)
+	JSONReport   string   // Path to write the JSON report to in addition to the report in the output format
+	TemplateFile string   // Path to a Go text/template file rendering the markdown report (default built-in template)
+	TopJobs      int      // Number of jobs to list by billable time in this billing cycle (0 disables the breakdown)
+
+	Sort      SortKey   // Key to sort the workflow tables by (default name)
+	SortOrder SortOrder // Order to sort the workflow tables in (default ascending by name, descending by the other keys)
+	Top       int       // Number of workflows listed in each workflow table, with the rest collapsed into an "Other" row (0 lists all workflows)
+
+	Filter WorkflowFilter // Conditions to include and exclude workflows from the report
+
+	LargerRunners bool              // Aggregate the billable time of the jobs run on larger runners in this billing cycle
+	RunnerSKUs    map[string]string // Map of runner labels or runner group names to larger runner SKUs
+	Storage       bool              // List the artifacts and the cache usage of each repository with the estimated storage cost
+
+	BudgetFile   string  // Path to a JSON file with the budget thresholds
+	Budget       *Budget // Budget used instead of the one of BudgetFile (e.g. from the configuration file)
+	FailOnBudget bool    // Return ErrBudgetExceeded when an error threshold of the budget is crossed
+
+	Concurrency   int  // Maximum number of requests sent to the GitHub API concurrently (default DefaultConcurrency)
+	CollectErrors bool // Report all failed requests instead of stopping at the first error
+	Tolerant      bool // Render the report without the workflows that could not be fetched and list them with the reason
+	FailOnPartial bool // Return ErrPartialReport when some workflows could not be fetched in tolerant mode
+
+	MaxRetries       int           // Maximum number of retries of a request failed by the rate limits or server errors (0 disables retries)
+	MaxRateLimitWait time.Duration // Maximum time to wait for a rate limit to reset before giving up
+
+	APIURL    string // GitHub API URL, e.g. https://github.example.com/api/v3 for GitHub Enterprise Server (default $GITHUB_API_URL or https://api.github.com)
+	UploadURL string // GitHub upload URL (default derived from the API URL)
+
+	AppID             int64  // GitHub App ID. If set, the requests are authenticated as the installation of the GitHub App instead of GITHUB_TOKEN
+	AppPrivateKeyFile string // Path to the PEM private key of the GitHub App (default $GITHUB_APP_PRIVATE_KEY)
+
+	SnapshotFile string // Path to a JSON file keeping the snapshots of the previous runs to show the change since the previous run
+	SnapshotKeep int    // Number of snapshots kept in the snapshot file (0 keeps all snapshots)
+
+	BillingCycleDay int    // Day of the month the billing cycle starts on (1-28, default 1)
+	Forecast        bool   // Project the billable time to the end of the billing cycle
+	Plan            string // GitHub plan to compare the forecast with the included minutes (e.g. team)
+
+	BillingSummary bool // Render the Actions billing summary of the organization or the repository owner above the workflow tables
+
+	CommentOn         int    // Number of the issue or pull request to post the markdown report to as a comment, updated on each run (0 disables the comment)
+	TrackingIssue     bool   // Open a tracking issue with the markdown report for each billing cycle, updated on each run
+	PublishRepository string // Repository in owner/repo format to post the comment and the tracking issue to (default the repository of the report, or $GITHUB_REPOSITORY for an organization)
+
+	SlackWebhookURL string // Slack incoming webhook URL to post the summary of the report to
+	WebhookURL      string // Webhook URL to post the JSON report to
+	NotifyDryRun    bool   // Print the payloads of the notifications to stderr instead of sending them
+}
 
-// WorkflowBillableTime represents the total billable time for each environment in a workflow
+// Report represents the billable times for a repository or all private repositories of an organization
+type Report struct {
+	Organization string                   // Organization name (empty for a single repository report)
+	Repositories []RepositoryBillableTime // Billable times for each repository
+	Pricing      Pricing                  // Pricing used to estimate the weighted minutes and the cost
+	GeneratedAt  time.Time                // Time the report was generated
+	Previous     *Snapshot                // Previous snapshot to compare with (nil if there is none)
+	Forecast     *Forecast                // Billable time projected to the end of the billing cycle (nil if not requested)
+	Failures     []FetchFailure           // Workflows and repositories that could not be fetched (only with Options.Tolerant)
+	Billing      *BillingSummary          // Actions billing summary of the owner (nil if not requested)
+	Sort         WorkflowSort             // How the workflows are sorted and limited in the workflow tables
+
+	Filtered          bool // Whether the workflows are filtered by Options.Filter
+	ExcludedWorkflows int  // Number of the workflows excluded by Options.Filter across all repositories
+}
+
+// RepositoryBillableTime represents the billable times for the workflows in a repository
+type RepositoryBillableTime struct {
+	Repository string                // Repository name in owner/repo format
+	Workflows  WorkflowBillableTimes // Billable times for each workflow in the repository
+	Jobs       []JobBillableTime     // Top jobs by billable time in this billing cycle (only with Options.TopJobs)
+
+	LargerRunners []LargerRunnerBillableTime // Billable time on larger runners in this billing cycle (only with Options.LargerRunners)
+	Storage       *RepositoryStorage         // Storage used by the artifacts and the caches (only with Options.Storage)
+
+	ExcludedWorkflows int // Number of the workflows excluded by Options.Filter
+}
+
+// WorkflowBillableTimes represents a list of WorkflowBillableTime.
+// Each entry is identified by its workflow ID, so workflows sharing the same name are kept apart.
+type WorkflowBillableTimes []WorkflowBillableTime
+
+// WorkflowBillableTime represents a workflow and its total billable time for each environment
 type WorkflowBillableTime struct {
-	Ubuntu  int64 // Total billable time for the Ubuntu environment (in minutes)
-	Windows int64 // Total billable time for the Windows environment (in minutes)
-	Macos   int64 // Total billable time for the Mac environment (in minutes)
+	ID    int64  // Workflow ID
+	Name  string // Workflow display name
+	Path  string // Workflow file path (e.g. .github/workflows/ci.yml)
+	State string // Workflow state (e.g. active, disabled_manually)
+	BillableTime
+}
+
+// BillableTime represents the total billable time for each environment
+type BillableTime struct {
+	Ubuntu    int64 // Total billable time for the Ubuntu environment (in minutes)
+	Windows   int64 // Total billable time for the Windows environment (in minutes)
+	Macos     int64 // Total billable time for the Mac environment (in minutes)
+	UbuntuMS  int64 // Total billable time for the Ubuntu environment (in milliseconds)
+	WindowsMS int64 // Total billable time for the Windows environment (in milliseconds)
+	MacosMS   int64 // Total billable time for the Mac environment (in milliseconds)
+}
+
+// render renders the report in the specified format. The markdown report is rendered with the template.
+func (r Report) render(format Format, tmpl *template.Template) (string, error) {
+	switch format {
+	case FormatJSON:
+		return r.generateJSONReport()
+	case FormatCSV:
+		return r.generateCSVReport()
+	case FormatOpenMetrics:
+		return r.generateOpenMetricsReport()
+	case FormatMarkdown:
+		return r.executeTemplate(tmpl)
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
 }
 
-// GenerateMarkdownReport generates a markdown-formatted report based on the provided WorkflowBillableTimes data.
-// It includes a title, a table of billable times for each workflow, and a note.
-func (w WorkflowBillableTimes) generateMarkdownReport() string {
+// multiRepository returns true if the report covers several repositories, either of an organization or listed
+func (r Report) multiRepository() bool {
+	return r.Organization != "" || len(r.Repositories) > 1
+}
+
+// generateOrganizationTotalTable generates a markdown table of the totals for each repository in the organization with a grand total row
+func (r Report) generateOrganizationTotalTable() string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("# %s\n\n", title))
-	sb.WriteString(w.generateMarkdownTable())
-	sb.WriteString(w.calculateTotal().formatBoldMarkdownRow("Total"))
-	sb.WriteString(fmt.Sprintf("\n%s\n", note))
+	columns := append([]string{"Repository"}, billableTimeColumns...)
+	if r.Previous != nil {
+		columns = append(columns, deltaColumns...)
+	}
+	sb.WriteString(formatMarkdownHeader(columns))
+
+	var previousTotal BillableTime
+	for _, rbt := range r.Repositories {
+		total := rbt.Workflows.calculateTotal()
+		var deltas []string
+		if previous := r.previousWorkflows(rbt.Repository); previous != nil {
+			previousRepositoryTotal := rbt.Workflows.calculatePreviousTotal(previous)
+			previousTotal = previousTotal.add(previousRepositoryTotal)
+			deltas = formatDeltas(total, previousRepositoryTotal)
+		}
+		sb.WriteString(total.formatMarkdownRow(rbt.Repository, r.Pricing, deltas...))
+	}
+	var deltas []string
+	if r.Previous != nil {
+		deltas = formatDeltas(r.calculateTotal(), previousTotal)
+	}
+	sb.WriteString(r.calculateTotal().formatBoldMarkdownRow(filteredTitle("Grand Total", r.Filtered), r.Pricing, deltas...))
+	if r.ExcludedWorkflows > 0 {
+		sb.WriteString(filterNote(r.ExcludedWorkflows))
+	}
 
 	return sb.String()
 }
 
+// previousWorkflows returns the billable times of the workflows of the repository in the previous snapshot.
+// It returns nil if there is no previous snapshot.
+func (r Report) previousWorkflows(repository string) map[int64]BillableTime {
+	if r.Previous == nil {
+		return nil
+	}
+	return r.Previous.workflows(repository)
+}
+
+// calculateTotal calculates the total billable time for each environment across all repositories
+func (r Report) calculateTotal() BillableTime {
+	var totalBillableTime BillableTime
+	for _, rbt := range r.Repositories {
+		totalBillableTime = totalBillableTime.add(rbt.Workflows.calculateTotal())
+	}
+	return totalBillableTime
+}
+
 // calculateTotal calculates the total billable time for each environment across all workflows
-func (w WorkflowBillableTimes) calculateTotal() WorkflowBillableTime {
-	var totalBillableTime WorkflowBillableTime
-	for _, billableTime := range w {
-		totalBillableTime.Ubuntu += billableTime.Ubuntu
-		totalBillableTime.Windows += billableTime.Windows
-		totalBillableTime.Macos += billableTime.Macos
+func (w WorkflowBillableTimes) calculateTotal() BillableTime {
+	var totalBillableTime BillableTime
+	for _, wbt := range w {
+		totalBillableTime = totalBillableTime.add(wbt.BillableTime)
 	}
 	return totalBillableTime
 }
 
-// generateMarkdownTable generates a markdown-formatted table of billable times for each workflow.
-// The table includes the workflow name and the billable times for Ubuntu, Windows, and macOS.
-func (w WorkflowBillableTimes) generateMarkdownTable() string {
+// calculatePreviousTotal calculates the total billable time for each environment of the workflows in the previous snapshot
+func (w WorkflowBillableTimes) calculatePreviousTotal(previous map[int64]BillableTime) BillableTime {
+	var totalBillableTime BillableTime
+	for _, wbt := range w {
+		totalBillableTime = totalBillableTime.add(previous[wbt.ID])
+	}
+	return totalBillableTime
+}
+
+// generateMarkdownTable generates a markdown-formatted table of billable times for each workflow with a total row.
+// The table includes the workflow name, the billable times for Ubuntu, Windows, and macOS,
+// and the weighted minutes and the estimated cost based on the pricing.
+// If previous is not nil, the change of the billable times since the previous snapshot is also included.
+// The workflows are sorted as specified by s, and the workflows beyond s.Top are collapsed into a single row.
+// The total row is titled totalTitle.
+func (w WorkflowBillableTimes) generateMarkdownTable(pricing Pricing, previous map[int64]BillableTime, s WorkflowSort, totalTitle string) string {
 	var sb strings.Builder
-	sb.WriteString(tableHeader)
-	sb.WriteString(tableSeparator)
+	columns := append([]string{"Workflow"}, billableTimeColumns...)
+	if previous != nil {
+		columns = append(columns, deltaColumns...)
+	}
+	sb.WriteString(formatMarkdownHeader(columns))
 
-	workflowNames := w.sortWorkflowNames()
+	workflows := w.sortBy(s, pricing)
+	var others WorkflowBillableTimes
+	if s.Top > 0 && len(workflows) > s.Top {
+		workflows, others = workflows[:s.Top], workflows[s.Top:]
+	}
+	for _, wbt := range workflows {
+		var deltas []string
+		if previous != nil {
+			deltas = formatDeltas(wbt.BillableTime, previous[wbt.ID])
+		}
+		sb.WriteString(wbt.formatMarkdownRow(w.displayName(wbt), pricing, deltas...))
+	}
+	if len(others) > 0 {
+		var deltas []string
+		if previous != nil {
+			deltas = formatDeltas(others.calculateTotal(), others.calculatePreviousTotal(previous))
+		}
+		sb.WriteString(others.calculateTotal().formatMarkdownRow(otherRowTitle(len(others)), pricing, deltas...))
+	}
 
-	for _, name := range workflowNames {
-		sb.WriteString(w[name].formatMarkdownRow(name))
+	var deltas []string
+	if previous != nil {
+		deltas = formatDeltas(w.calculateTotal(), w.calculatePreviousTotal(previous))
 	}
+	sb.WriteString(w.calculateTotal().formatBoldMarkdownRow(totalTitle, pricing, deltas...))
 
 	return sb.String()
 }
 
-// sortWorkflowNames returns a sorted slice of workflow names.
-func (w WorkflowBillableTimes) sortWorkflowNames() []string {
-	var workflowNames []string
-	for name := range w {
-		workflowNames = append(workflowNames, name)
+// sortWorkflows returns a copy of the workflows sorted by name, then by path.
+func (w WorkflowBillableTimes) sortWorkflows() WorkflowBillableTimes {
+	sorted := make(WorkflowBillableTimes, len(w))
+	copy(sorted, w)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+	return sorted
+}
+
+// displayName returns the name to display for the workflow.
+// If another workflow shares the same name, the file path is appended to tell them apart.
+func (w WorkflowBillableTimes) displayName(wbt WorkflowBillableTime) string {
+	for _, other := range w {
+		if other.ID != wbt.ID && other.Name == wbt.Name {
+			return fmt.Sprintf("%s (%s)", wbt.Name, wbt.Path)
+		}
 	}
-	sort.Strings(workflowNames)
-	return workflowNames
+	return wbt.Name
 }
 
-// formatMarkdownRow formats the billable time for each environment as a markdown table row
-func (e WorkflowBillableTime) formatMarkdownRow(title string) string {
-	return fmt.Sprintf("| %s | %d | %d | %d |\n", title, e.Ubuntu, e.Windows, e.Macos)
+// add returns the sum of the billable times for each environment
+func (e BillableTime) add(other BillableTime) BillableTime {
+	return BillableTime{
+		Ubuntu:    e.Ubuntu + other.Ubuntu,
+		Windows:   e.Windows + other.Windows,
+		Macos:     e.Macos + other.Macos,
+		UbuntuMS:  e.UbuntuMS + other.UbuntuMS,
+		WindowsMS: e.WindowsMS + other.WindowsMS,
+		MacosMS:   e.MacosMS + other.MacosMS,
+	}
 }
 
-// formatBoldMarkdownRow formats the billable time for each environment as a bold markdown table row
-func (e WorkflowBillableTime) formatBoldMarkdownRow(title string) string {
-	return fmt.Sprintf("| **%s** | **%d** | **%d** | **%d** |\n", title, e.Ubuntu, e.Windows, e.Macos)
+// sub returns the difference of the billable times for each environment
+func (e BillableTime) sub(other BillableTime) BillableTime {
+	return BillableTime{
+		Ubuntu:    e.Ubuntu - other.Ubuntu,
+		Windows:   e.Windows - other.Windows,
+		Macos:     e.Macos - other.Macos,
+		UbuntuMS:  e.UbuntuMS - other.UbuntuMS,
+		WindowsMS: e.WindowsMS - other.WindowsMS,
+		MacosMS:   e.MacosMS - other.MacosMS,
+	}
+}
+
+// markdownCells returns the billable time for each environment, the weighted minutes and the cost as markdown table cells
+func (e BillableTime) markdownCells(pricing Pricing) []string {
+	return []string{
+		strconv.FormatInt(e.Ubuntu, 10),
+		strconv.FormatInt(e.Windows, 10),
+		strconv.FormatInt(e.Macos, 10),
+		formatMinutes(pricing.weightedMinutes(e)),
+		formatCost(pricing.cost(e)),
+	}
 }
 
-// CreateReport retrieves billable time for workflows and generates a markdown report
-func CreateReport(repository string) error {
-	owner, repo, err := extractOwnerAndRepo(repository)
+// formatMarkdownRow formats the billable time for each environment, the weighted minutes and the cost as a markdown table row.
+// extra cells are appended to the end of the row.
+func (e BillableTime) formatMarkdownRow(title string, pricing Pricing, extra ...string) string {
+	cells := append(append([]string{title}, e.markdownCells(pricing)...), extra...)
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
+
+// formatBoldMarkdownRow formats the billable time for each environment, the weighted minutes and the cost as a bold markdown table row.
+// extra cells are appended to the end of the row.
+func (e BillableTime) formatBoldMarkdownRow(title string, pricing Pricing, extra ...string) string {
+	cells := append(append([]string{title}, e.markdownCells(pricing)...), extra...)
+	for i, cell := range cells {
+		cells[i] = "**" + cell + "**"
+	}
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
+
+// formatMarkdownHeader formats the header row and the separator row of a markdown table
+func formatMarkdownHeader(columns []string) string {
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	return "| " + strings.Join(columns, " | ") + " |\n| " + strings.Join(separators, " | ") + " |\n"
+}
+
+// formatDeltas formats the change of the billable time for each environment since the previous snapshot (e.g. +10, -3, 0)
+func formatDeltas(current, previous BillableTime) []string {
+	delta := current.sub(previous)
+	deltas := make([]string, 0, 3)
+	for _, minutes := range []int64{delta.Ubuntu, delta.Windows, delta.Macos} {
+		if minutes > 0 {
+			deltas = append(deltas, fmt.Sprintf("+%d", minutes))
+		} else {
+			deltas = append(deltas, strconv.FormatInt(minutes, 10))
+		}
+	}
+	return deltas
+}
+
+// CreateReport retrieves billable time for workflows and generates a report in the specified format.
+// The report is written to opts.OutputPath if specified. Otherwise a markdown report is appended to $GITHUB_STEP_SUMMARY,
+// and the other formats (or markdown outside GitHub Actions) are written to stdout.
+// Requests to the GitHub API are cancelled when ctx is done.
+func CreateReport(ctx context.Context, opts Options) error {
+	if opts.Format == "" {
+		opts.Format = FormatMarkdown
+	}
+	if err := opts.Format.validate(); err != nil {
+		return err
+	}
+	if opts.TemplateFile != "" && opts.Format != FormatMarkdown {
+		return fmt.Errorf("template is only supported for the %s format", FormatMarkdown)
+	}
+	workflowSort, err := WorkflowSort{Key: opts.Sort, Order: opts.SortOrder, Top: opts.Top}.normalize()
 	if err != nil {
 		return err
 	}
+	if err := opts.Filter.validate(); err != nil {
+		return err
+	}
+	if opts.CommentOn < 0 {
+		return fmt.Errorf("invalid issue or pull request number: %d", opts.CommentOn)
+	}
+	if opts.BillingSummary {
+		if _, err := reportOwner(opts); err != nil {
+			return err
+		}
+	}
+	// the repository to publish to is resolved before fetching, so a missing one does not fail the run after the report is written
+	if opts.CommentOn != 0 || opts.TrackingIssue {
+		if _, _, err := publishTarget(opts); err != nil {
+			return err
+		}
+	}
+	if opts.BillingCycleDay == 0 {
+		opts.BillingCycleDay = 1
+	}
+	if opts.BillingCycleDay < 1 || opts.BillingCycleDay > 28 {
+		return fmt.Errorf("billing cycle day must be between 1 and 28: %d", opts.BillingCycleDay)
+	}
+	if _, ok := Plans[opts.Plan]; opts.Plan != "" && !ok {
+		return fmt.Errorf("unsupported plan: %s", opts.Plan)
+	}
 
-	client := createGitHubClient()
-	workflows, err := fetchWorkflows(client, owner, repo)
+	pricing, err := resolvePricing(opts)
+	if err != nil {
+		return err
+	}
+	budget, err := resolveBudget(opts)
+	if err != nil {
+		return err
+	}
+	tmpl, err := loadTemplate(opts.TemplateFile)
+	if err != nil {
+		return err
+	}
+	var snapshots snapshotStore
+	if opts.SnapshotFile != "" {
+		snapshots, err = loadSnapshots(opts.SnapshotFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := createGitHubClient(opts)
 	if err != nil {
 		return err
 	}
 
-	wbt, err := generateWorkflowBillableTimes(client, owner, repo, workflows)
+	report, err := createReport(ctx, client, opts)
 	if err != nil {
 		return err
 	}
-	err = appendToFile(getOutputPath(), wbt.generateMarkdownReport())
+	if opts.BillingSummary {
+		owner, _ := reportOwner(opts) // already validated before fetching
+		summary, err := fetchOwnerBillingSummary(ctx, client, owner)
+		if err != nil {
+			return err
+		}
+		report.Billing = &summary
+	}
+	report.Pricing = pricing
+	report.Sort = workflowSort
+	report.GeneratedAt = time.Now().UTC()
+	report.Previous = snapshots.latest(billingCycleStart(report.GeneratedAt, opts.BillingCycleDay))
+	if opts.Forecast {
+		forecast := newForecast(report, snapshots, opts.BillingCycleDay, opts.Plan)
+		report.Forecast = &forecast
+	}
+
+	content, err := report.render(opts.Format, tmpl)
 	if err != nil {
 		return err
 	}
 
+	if err := writeOutput(opts.OutputPath, opts.Format, content); err != nil {
+		return err
+	}
+
+	jsonReport := opts.JSONReport
+	if jsonReport != "" {
+		data, err := report.render(FormatJSON, nil)
+		if err != nil {
+			return err
+		}
+		if err := writeToFile(jsonReport, data); err != nil {
+			return err
+		}
+	} else if opts.Format == FormatJSON && opts.OutputPath != "" {
+		jsonReport = opts.OutputPath
+	}
+	if err := writeActionOutputs(report, jsonReport); err != nil {
+		return err
+	}
+
+	if opts.SnapshotFile != "" {
+		if err := snapshots.append(newSnapshot(report), opts.SnapshotKeep).save(opts.SnapshotFile); err != nil {
+			return err
+		}
+	}
+
+	if opts.CommentOn != 0 || opts.TrackingIssue {
+		markdown := content
+		if opts.Format != FormatMarkdown {
+			if markdown, err = report.render(FormatMarkdown, tmpl); err != nil {
+				return err
+			}
+		}
+		if err := publishReport(ctx, client, opts, report, markdown); err != nil {
+			return err
+		}
+	}
+
+	if opts.SlackWebhookURL != "" || opts.WebhookURL != "" {
+		subject, err := reportSubject(opts)
+		if err != nil {
+			return err
+		}
+		if err := newNotifier(opts, os.Stderr).notify(ctx, opts, report, subject); err != nil {
+			return err
+		}
+	}
+
+	// workflow commands are printed to stderr, so they never mix into a report written to stdout
+	for _, f := range report.Failures {
+		fmt.Fprint(os.Stderr, f.formatWorkflowCommand())
+	}
+	violations := budget.evaluate(report)
+	for _, v := range violations {
+		fmt.Fprint(os.Stderr, v.formatWorkflowCommand())
+	}
+	if opts.FailOnBudget && hasBudgetError(violations) {
+		return ErrBudgetExceeded
+	}
+	if opts.FailOnPartial && len(report.Failures) > 0 {
+		return ErrPartialReport
+	}
+
 	return nil
 }
 
-// generateWorkflowBillableTime generates a WorkflowBillableTimes for the specified workflows
-func generateWorkflowBillableTimes(client *github.Client, owner, repo string, workflows []*github.Workflow) (WorkflowBillableTimes, error) {
-	wbt := make(WorkflowBillableTimes)
+// createReport creates a Report for opts.Organization if set, for opts.Repositories if set, or for opts.Repository
+func createReport(ctx context.Context, client *github.Client, opts Options) (Report, error) {
+	var report Report
+	var err error
+	switch {
+	case opts.Organization != "":
+		report, err = createOrganizationReport(ctx, client, opts)
+	case len(opts.Repositories) > 0:
+		report, err = createRepositoriesReport(ctx, client, opts)
+	default:
+		report, err = createRepositoryReport(ctx, client, opts)
+	}
+	if err != nil {
+		return Report{}, err
+	}
+	report.Filtered = !opts.Filter.isEmpty()
+	return report, nil
+}
+
+// createRepositoryReport creates a Report for a single repository
+func createRepositoryReport(ctx context.Context, client *github.Client, opts Options) (Report, error) {
+	owner, repo, err := extractOwnerAndRepo(opts.Repository)
+	if err != nil {
+		return Report{}, err
+	}
+
+	rbts, failures, err := generateRepositoryBillableTimes(ctx, client, owner, []string{repo}, opts)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return Report{Repositories: rbts, Failures: failures, ExcludedWorkflows: rbts[0].ExcludedWorkflows}, nil
+}
+
+// createRepositoriesReport creates a Report for the listed repositories, which may belong to several owners.
+// The repositories are grouped by owner in the listed order, and the ones without workflows are kept in the report.
+func createRepositoriesReport(ctx context.Context, client *github.Client, opts Options) (Report, error) {
+	if err := validateRepositories(opts.Repositories); err != nil {
+		return Report{}, fmt.Errorf("repositories%w", err)
+	}
 
-	for _, workflow := range workflows {
-		billMap, err := fetchWorkflowBillMap(client, owner, repo, *workflow.ID)
+	var owners []string
+	reposByOwner := make(map[string][]string)
+	for _, repository := range opts.Repositories {
+		owner, repo, _ := strings.Cut(repository, "/")
+		if _, ok := reposByOwner[owner]; !ok {
+			owners = append(owners, owner)
+		}
+		reposByOwner[owner] = append(reposByOwner[owner], repo)
+	}
+
+	var report Report
+	for _, owner := range owners {
+		rbts, failures, err := generateRepositoryBillableTimes(ctx, client, owner, reposByOwner[owner], opts)
 		if err != nil {
-			return nil, err
+			return Report{}, err
+		}
+		report.Repositories = append(report.Repositories, rbts...)
+		report.Failures = append(report.Failures, failures...)
+		for _, rbt := range rbts {
+			report.ExcludedWorkflows += rbt.ExcludedWorkflows
+		}
+	}
+
+	return report, nil
+}
+
+// createOrganizationReport creates a Report for all private repositories of the organization.
+// Repositories without workflows are omitted from the report.
+func createOrganizationReport(ctx context.Context, client *github.Client, opts Options) (Report, error) {
+	org := opts.Organization
+	repositories, err := fetchPrivateRepositories(ctx, client, org)
+	if err != nil {
+		return Report{}, err
+	}
+
+	repos := make([]string, 0, len(repositories))
+	for _, repository := range repositories {
+		repos = append(repos, repository.GetName())
+	}
+	rbts, failures, err := generateRepositoryBillableTimes(ctx, client, org, repos, opts)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Organization: org, Failures: failures}
+	for _, rbt := range rbts {
+		report.ExcludedWorkflows += rbt.ExcludedWorkflows
+		if len(rbt.Workflows) == 0 {
+			continue
+		}
+		report.Repositories = append(report.Repositories, rbt)
+	}
+
+	return report, nil
+}
+
+// repositoryWorkflow represents a workflow of a repository
+type repositoryWorkflow struct {
+	index    int    // Index of the repository
+	repo     string // Repository name
+	workflow *github.Workflow
+}
+
+// generateRepositoryBillableTimes generates a RepositoryBillableTime for each of the repositories of the owner, in the same order.
+// The workflows of all repositories and their billable times are fetched by a worker pool of opts.Concurrency workers.
+// If opts.TopJobs or opts.LargerRunners is set, the jobs in this billing cycle are also aggregated.
+// If opts.Tolerant is set, the workflows and repositories failed to fetch are returned as failures instead of an error.
+func generateRepositoryBillableTimes(ctx context.Context, client *github.Client, owner string, repos []string, opts Options) ([]RepositoryBillableTime, []FetchFailure, error) {
+	pool := newWorkerPool(opts.Concurrency, opts.CollectErrors)
+	var failures []FetchFailure
+
+	workflows, err := runPool(ctx, pool, repos, tolerate(opts.Tolerant, func(ctx context.Context, repo string) ([]*github.Workflow, error) {
+		return fetchWorkflows(ctx, client, owner, repo)
+	}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rbts := make([]RepositoryBillableTime, len(repos))
+	// the workflows included by the filter, so the runs and the artifacts of the excluded workflows are left out as well
+	included := make([]workflowSet, len(repos))
+	var rws []repositoryWorkflow
+	for i, repo := range repos {
+		rbts[i].Repository = owner + "/" + repo
+		if err := workflows[i].err; err != nil {
+			failures = append(failures, FetchFailure{Repository: owner + "/" + repo, Reason: err.Error()})
+			continue
+		}
+		if !opts.Filter.isEmpty() {
+			included[i] = workflowSet{}
+		}
+		for _, workflow := range workflows[i].value {
+			if !opts.Filter.matches(workflow) {
+				rbts[i].ExcludedWorkflows++
+				continue
+			}
+			if included[i] != nil {
+				included[i][workflow.GetID()] = true
+			}
+			rws = append(rws, repositoryWorkflow{index: i, repo: repo, workflow: workflow})
+		}
+	}
+	wbts, err := runPool(ctx, pool, rws, tolerate(opts.Tolerant, func(ctx context.Context, rw repositoryWorkflow) (WorkflowBillableTime, error) {
+		return generateWorkflowBillableTime(ctx, client, owner, rw.repo, rw.workflow)
+	}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, rw := range rws {
+		if err := wbts[i].err; err != nil {
+			failures = append(failures, FetchFailure{
+				Repository: owner + "/" + rw.repo,
+				WorkflowID: rw.workflow.GetID(),
+				Workflow:   rw.workflow.GetName(),
+				Path:       rw.workflow.GetPath(),
+				Reason:     err.Error(),
+			})
+			continue
+		}
+		rbts[rw.index].Workflows = append(rbts[rw.index].Workflows, wbts[i].value)
+	}
+
+	for i, repo := range repos {
+		if len(rbts[i].Workflows) == 0 {
+			continue
+		}
+		if opts.TopJobs > 0 || opts.LargerRunners {
+			err := generateRepositoryJobs(ctx, client, pool, owner, repo, &rbts[i], included[i], opts)
+			if err != nil && (!opts.Tolerant || ctx.Err() != nil) {
+				return nil, nil, err
+			}
+			if err != nil {
+				failures = append(failures, FetchFailure{Repository: owner + "/" + repo, Reason: "failed to fetch the jobs: " + err.Error()})
+			}
+		}
+		if opts.Storage {
+			storage, err := generateRepositoryStorage(ctx, client, pool, owner, repo, rbts[i].Workflows, included[i])
+			if err != nil && (!opts.Tolerant || ctx.Err() != nil) {
+				return nil, nil, err
+			}
+			if err != nil {
+				failures = append(failures, FetchFailure{Repository: owner + "/" + repo, Reason: "failed to fetch the storage: " + err.Error()})
+			} else {
+				rbts[i].Storage = &storage
+			}
 		}
+	}
+
+	return rbts, failures, nil
+}
 
-		wbt[*workflow.Name] = WorkflowBillableTime{
-			Ubuntu:  getMinutesForEnv(billMap, "UBUNTU"),
-			Windows: getMinutesForEnv(billMap, "WINDOWS"),
-			Macos:   getMinutesForEnv(billMap, "MACOS"),
+// generateRepositoryJobs aggregates the jobs in this billing cycle into the RepositoryBillableTime as specified by opts.TopJobs and opts.LargerRunners.
+// Only the runs of the included workflows are aggregated.
+func generateRepositoryJobs(ctx context.Context, client *github.Client, pool workerPool, owner, repo string, rbt *RepositoryBillableTime, included workflowSet, opts Options) error {
+	runJobs, err := fetchWorkflowRunJobs(ctx, client, pool, owner, repo, billingCycleStart(time.Now(), opts.BillingCycleDay), included)
+	if err != nil {
+		return err
+	}
+	if opts.TopJobs > 0 {
+		rbt.Jobs, err = generateJobBillableTimes(ctx, client, pool, owner, repo, runJobs, opts.TopJobs)
+		if err != nil {
+			return err
 		}
 	}
+	if opts.LargerRunners {
+		rbt.LargerRunners = generateLargerRunnerBillableTimes(runJobs, opts.RunnerSKUs)
+	}
+	return nil
+}
+
+// generateWorkflowBillableTime generates a WorkflowBillableTime for the specified workflow
+func generateWorkflowBillableTime(ctx context.Context, client *github.Client, owner, repo string, workflow *github.Workflow) (WorkflowBillableTime, error) {
+	billMap, err := fetchWorkflowBillMap(ctx, client, owner, repo, workflow.GetID())
+	if err != nil {
+		return WorkflowBillableTime{}, err
+	}
 
-	return wbt, nil
+	return WorkflowBillableTime{
+		ID:    workflow.GetID(),
+		Name:  workflow.GetName(),
+		Path:  workflow.GetPath(),
+		State: workflow.GetState(),
+		BillableTime: BillableTime{
+			Ubuntu:    getMinutesForEnv(billMap, "UBUNTU"),
+			Windows:   getMinutesForEnv(billMap, "WINDOWS"),
+			Macos:     getMinutesForEnv(billMap, "MACOS"),
+			UbuntuMS:  getMillisecondsForEnv(billMap, "UBUNTU"),
+			WindowsMS: getMillisecondsForEnv(billMap, "WINDOWS"),
+			MacosMS:   getMillisecondsForEnv(billMap, "MACOS"),
+		},
+	}, nil
 }
@@ -1,119 +1,641 @@
 package bills
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-github/v60/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
 )
 
-func Test_generateWorkflowBillableTimes(t *testing.T) {
-	type args struct {
-		client    *github.Client
-		owner     string
-		repo      string
-		workflows []*github.Workflow
+func Test_generateWorkflowBillableTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *github.Client
+		workflow *github.Workflow
+		want     WorkflowBillableTime
+		wantErr  bool
+	}{
+		{
+			name:   "basic",
+			client: mockClientForWorkflowUsage("basic"),
+			workflow: &github.Workflow{
+				Name:  github.String("CI"),
+				ID:    github.Int64(123),
+				Path:  github.String(".github/workflows/ci.yml"),
+				State: github.String("active"),
+			},
+			want: WorkflowBillableTime{
+				ID:           123,
+				Name:         "CI",
+				Path:         ".github/workflows/ci.yml",
+				State:        "active",
+				BillableTime: BillableTime{Ubuntu: 1, Windows: 10, Macos: 0, UbuntuMS: 60000, WindowsMS: 600000},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "ratelimit",
+			client: mockClientForWorkflowUsage("ratelimit"),
+			workflow: &github.Workflow{
+				Name: github.String("workflow1"),
+				ID:   github.Int64(123),
+			},
+			want:    WorkflowBillableTime{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generateWorkflowBillableTime(context.Background(), tt.client, "owner", "repo", tt.workflow)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateWorkflowBillableTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateWorkflowBillableTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generateRepositoryBillableTimes(t *testing.T) {
+	// 20 workflows in repo1 so that the workers finish out of order
+	var many []*github.Workflow
+	var wantMany WorkflowBillableTimes
+	for id := int64(1); id <= 20; id++ {
+		many = append(many, &github.Workflow{ID: github.Int64(id), Name: github.String(fmt.Sprintf("workflow%d", id))})
+		wantMany = append(wantMany, WorkflowBillableTime{
+			ID:           id,
+			Name:         fmt.Sprintf("workflow%d", id),
+			BillableTime: BillableTime{Ubuntu: id, UbuntuMS: id * 60000},
+		})
 	}
+	workflows := map[string][]*github.Workflow{
+		"repo1": many,
+		"repo2": {
+			{ID: github.Int64(31), Name: github.String("CI"), Path: github.String(".github/workflows/ci.yml")},
+			{ID: github.Int64(32), Name: github.String("CI"), Path: github.String(".github/workflows/ci-copy.yml")},
+		},
+		"repo3": {},
+	}
+
 	tests := []struct {
-		name    string
-		args    args
-		want    WorkflowBillableTimes
-		wantErr bool
+		name         string
+		failing      map[int64]bool
+		opts         Options
+		want         []RepositoryBillableTime
+		wantFailures []FetchFailure
+		wantErr      bool
+		wantErrors   int
 	}{
 		{
 			name: "basic",
-			args: args{
-				client: mockClientForWorkflowUsage("basic"),
-				owner:  "owner",
-				repo:   "repo",
-				workflows: []*github.Workflow{
-					{
-						Name: github.String("workflow1"),
-						ID:   github.Int64(123),
+			opts: Options{Concurrency: 4},
+			want: []RepositoryBillableTime{
+				{Repository: "owner/repo1", Workflows: wantMany},
+				{
+					Repository: "owner/repo2",
+					Workflows: WorkflowBillableTimes{
+						{ID: 31, Name: "CI", Path: ".github/workflows/ci.yml", BillableTime: BillableTime{Ubuntu: 31, UbuntuMS: 31 * 60000}},
+						{ID: 32, Name: "CI", Path: ".github/workflows/ci-copy.yml", BillableTime: BillableTime{Ubuntu: 32, UbuntuMS: 32 * 60000}},
 					},
 				},
-			},
-			want: WorkflowBillableTimes{
-				"workflow1": WorkflowBillableTime{
-					Ubuntu:  1,
-					Windows: 10,
-					Macos:   0,
-				},
+				{Repository: "owner/repo3"},
 			},
 			wantErr: false,
 		},
 		{
-			name: "ratelimit",
-			args: args{
-				client: mockClientForWorkflowUsage("ratelimit"),
-				owner:  "owner",
-				repo:   "repo",
-				workflows: []*github.Workflow{
-					{
-						Name: github.String("workflow1"),
-						ID:   github.Int64(123),
+			name:    "tolerant",
+			failing: map[int64]bool{3: true, 31: true},
+			opts:    Options{Concurrency: 4, Tolerant: true},
+			want: []RepositoryBillableTime{
+				{Repository: "owner/repo1", Workflows: append(append(WorkflowBillableTimes{}, wantMany[:2]...), wantMany[3:]...)},
+				{
+					Repository: "owner/repo2",
+					Workflows: WorkflowBillableTimes{
+						{ID: 32, Name: "CI", Path: ".github/workflows/ci-copy.yml", BillableTime: BillableTime{Ubuntu: 32, UbuntuMS: 32 * 60000}},
 					},
 				},
+				{Repository: "owner/repo3"},
 			},
-			want:    nil,
-			wantErr: true,
+			wantFailures: []FetchFailure{
+				{Repository: "owner/repo1", WorkflowID: 3, Workflow: "workflow3", Reason: "500 failed"},
+				{Repository: "owner/repo2", WorkflowID: 31, Workflow: "CI", Path: ".github/workflows/ci.yml", Reason: "500 failed"},
+			},
+			wantErr: false,
+		},
+		{
+			name:       "first error",
+			failing:    map[int64]bool{3: true, 31: true},
+			opts:       Options{Concurrency: 4},
+			want:       nil,
+			wantErr:    true,
+			wantErrors: 1,
+		},
+		{
+			name:       "collect errors",
+			failing:    map[int64]bool{3: true, 31: true},
+			opts:       Options{Concurrency: 4, CollectErrors: true},
+			want:       nil,
+			wantErr:    true,
+			wantErrors: 2,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := generateWorkflowBillableTimes(tt.args.client, tt.args.owner, tt.args.repo, tt.args.workflows)
+			client := github.NewClient(mock.NewMockedHTTPClient(mockRepositoryOptions(workflows, tt.failing)...))
+			got, failures, err := generateRepositoryBillableTimes(context.Background(), client, "owner", []string{"repo1", "repo2", "repo3"}, tt.opts)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("generateWorkflowBillableTime() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("generateRepositoryBillableTimes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				errs := []error{err}
+				if joined, ok := err.(interface{ Unwrap() []error }); ok {
+					errs = joined.Unwrap()
+				}
+				if len(errs) != tt.wantErrors {
+					t.Errorf("generateRepositoryBillableTimes() error = %v, want %d errors", err, tt.wantErrors)
+				}
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("generateWorkflowBillableTime() = %v, want %v", got, tt.want)
+				t.Errorf("generateRepositoryBillableTimes() = %v, want %v", got, tt.want)
+			}
+			// compare the reasons partially as they include the URL of the mock server
+			if len(failures) != len(tt.wantFailures) {
+				t.Fatalf("generateRepositoryBillableTimes() failures = %v, want %v", failures, tt.wantFailures)
+			}
+			for i, f := range failures {
+				want := tt.wantFailures[i]
+				if !strings.Contains(f.Reason, want.Reason) {
+					t.Errorf("generateRepositoryBillableTimes() failures[%d].Reason = %v, want %v in it", i, f.Reason, want.Reason)
+				}
+				f.Reason = want.Reason
+				if f != want {
+					t.Errorf("generateRepositoryBillableTimes() failures[%d] = %v, want %v", i, f, want)
+				}
 			}
 		})
 	}
 }
 
-func TestWorkflowBillableTimes_generateMarkdownReport(t *testing.T) {
+// mockRepositoryOptions returns the mock options responding with the workflows of each repository,
+// and with the billable time of each workflow in minutes equal to its ID. Requests for the failing workflows fail.
+func mockRepositoryOptions(workflows map[string][]*github.Workflow, failing map[int64]bool) []mock.MockBackendOption {
+	return []mock.MockBackendOption{
+		mock.WithRequestMatchHandler(
+			mock.GetReposActionsWorkflowsByOwnerByRepo,
+			http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				// /repos/{owner}/{repo}/actions/workflows
+				repo := strings.Split(r.URL.Path, "/")[3]
+				_, _ = rw.Write(mock.MustMarshal(github.Workflows{Workflows: workflows[repo]}))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposActionsWorkflowsTimingByOwnerByRepoByWorkflowId,
+			http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				// /repos/{owner}/{repo}/actions/workflows/{workflow_id}/timing
+				id, _ := strconv.ParseInt(strings.Split(r.URL.Path, "/")[6], 10, 64)
+				if failing[id] {
+					mock.WriteError(rw, http.StatusInternalServerError, "failed")
+					return
+				}
+				ms := id * 60000
+				_, _ = rw.Write(mock.MustMarshal(github.WorkflowUsage{
+					Billable: &github.WorkflowBillMap{
+						"UBUNTU": &github.WorkflowBill{TotalMS: &ms},
+					},
+				}))
+			}),
+		),
+	}
+}
+
+func TestReport_render_markdown(t *testing.T) {
 	workflowBillableTimes := WorkflowBillableTimes{
-		"Workflow2": WorkflowBillableTime{
-			Ubuntu:  180,
-			Windows: 30,
+		{
+			ID:           2,
+			Name:         "Workflow2",
+			BillableTime: BillableTime{Ubuntu: 180, Windows: 30},
 		},
-		"Workflow1": WorkflowBillableTime{
-			Ubuntu:  120,
-			Windows: 90,
-			Macos:   60,
+		{
+			ID:           1,
+			Name:         "Workflow1",
+			BillableTime: BillableTime{Ubuntu: 120, Windows: 90, Macos: 60},
 		},
 	}
 	want := `# Billable time for workflows in this billable cycle
 
-| Workflow | Ubuntu (min) | Windows (min) | Macos (min) |
-| --- | --- | --- | --- |
-| Workflow1 | 120 | 90 | 60 |
-| Workflow2 | 180 | 30 | 0 |
-| **Total** | **300** | **120** | **60** |
+| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| Workflow1 | 120 | 90 | 60 | 900 | $7.20 |
+| Workflow2 | 180 | 30 | 0 | 240 | $1.92 |
+| **Total** | **300** | **120** | **60** | **1140** | **$9.12** |
+
+Please note the following:
+
+- This list shows the execution time for each Workflow at the time this Action was executed.
+- Workflows that have been deleted at the time of execution will not be listed.
+- Execution times using Larger runners are not included in the workflow table. They are aggregated from the jobs when the larger runner breakdown is enabled.
+- Weighted minutes apply the minute multiplier of each runner OS, and costs are estimated from the price per minute without deducting the included minutes.
+`
+	wantOrg := `# Billable time for workflows in this billable cycle
+
+Organization: org (2 repositories)
+
+## org/repo1
+
+| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| Workflow1 | 120 | 90 | 60 | 900 | $7.20 |
+| Workflow2 | 180 | 30 | 0 | 240 | $1.92 |
+| **Total** | **300** | **120** | **60** | **1140** | **$9.12** |
+
+## org/repo2
+
+| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| Workflow1 | 10 | 0 | 5 | 60 | $0.48 |
+| **Total** | **10** | **0** | **5** | **60** | **$0.48** |
+
+## Total for org
+
+| Repository | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| org/repo1 | 300 | 120 | 60 | 1140 | $9.12 |
+| org/repo2 | 10 | 0 | 5 | 60 | $0.48 |
+| **Grand Total** | **310** | **120** | **65** | **1200** | **$9.60** |
+
+Please note the following:
+
+- This list shows the execution time for each Workflow at the time this Action was executed.
+- Workflows that have been deleted at the time of execution will not be listed.
+- Execution times using Larger runners are not included in the workflow table. They are aggregated from the jobs when the larger runner breakdown is enabled.
+- Weighted minutes apply the minute multiplier of each runner OS, and costs are estimated from the price per minute without deducting the included minutes.
+`
+	wantRepositories := `# Billable time for workflows in this billable cycle
+
+Repositories: 2
+
+## owner/repo1
+
+| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| Workflow1 | 120 | 90 | 60 | 900 | $7.20 |
+| Workflow2 | 180 | 30 | 0 | 240 | $1.92 |
+| **Total** | **300** | **120** | **60** | **1140** | **$9.12** |
+
+## owner/repo2
+
+| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| Workflow1 | 10 | 0 | 5 | 60 | $0.48 |
+| **Total** | **10** | **0** | **5** | **60** | **$0.48** |
+
+## Total
+
+| Repository | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| owner/repo1 | 300 | 120 | 60 | 1140 | $9.12 |
+| owner/repo2 | 10 | 0 | 5 | 60 | $0.48 |
+| **Grand Total** | **310** | **120** | **65** | **1200** | **$9.60** |
+
+Please note the following:
+
+- This list shows the execution time for each Workflow at the time this Action was executed.
+- Workflows that have been deleted at the time of execution will not be listed.
+- Execution times using Larger runners are not included in the workflow table. They are aggregated from the jobs when the larger runner breakdown is enabled.
+- Weighted minutes apply the minute multiplier of each runner OS, and costs are estimated from the price per minute without deducting the included minutes.
+`
+	wantDuplicate := `# Billable time for workflows in this billable cycle
+
+| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| CI (.github/workflows/ci-copy.yml) | 5 | 0 | 0 | 5 | $0.04 |
+| CI (.github/workflows/ci.yml) | 10 | 0 | 0 | 10 | $0.08 |
+| Release | 1 | 0 | 0 | 1 | $0.01 |
+| **Total** | **16** | **0** | **0** | **16** | **$0.13** |
+
+Please note the following:
+
+- This list shows the execution time for each Workflow at the time this Action was executed.
+- Workflows that have been deleted at the time of execution will not be listed.
+- Execution times using Larger runners are not included in the workflow table. They are aggregated from the jobs when the larger runner breakdown is enabled.
+- Weighted minutes apply the minute multiplier of each runner OS, and costs are estimated from the price per minute without deducting the included minutes.
+`
+	wantDelta := `# Billable time for workflows in this billable cycle
+
+| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) | Ubuntu (+/-) | Windows (+/-) | Macos (+/-) |
+| --- | --- | --- | --- | --- | --- | --- | --- | --- |
+| Workflow1 | 120 | 90 | 60 | 900 | $7.20 | +20 | 0 | +60 |
+| Workflow2 | 180 | 30 | 0 | 240 | $1.92 | +180 | +30 | 0 |
+| **Total** | **300** | **120** | **60** | **1140** | **$9.12** | **+200** | **+30** | **+60** |
 
 Please note the following:
 
 - This list shows the execution time for each Workflow at the time this Action was executed.
 - Workflows that have been deleted at the time of execution will not be listed.
-- Execution times using Larger runners are not included in the aggregation.
+- Execution times using Larger runners are not included in the workflow table. They are aggregated from the jobs when the larger runner breakdown is enabled.
+- Weighted minutes apply the minute multiplier of each runner OS, and costs are estimated from the price per minute without deducting the included minutes.
 `
+	wantBilling := `# Actions billing summary
+
+Account: owner (user)
+
+| Total (min) | Paid (min) | Included (min) | Remaining (min) |
+| --- | --- | --- | --- |
+| 1200 | 0 | 2000 | 800 |
+
+` + want
 	tests := []struct {
 		name string
-		w    WorkflowBillableTimes
+		r    Report
 		want string
 	}{
 		{
 			name: "basic",
-			w:    workflowBillableTimes,
+			r: Report{
+				Pricing: DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{Repository: "owner/repo", Workflows: workflowBillableTimes},
+				},
+			},
 			want: want,
 		},
+		{
+			name: "organization",
+			r: Report{
+				Organization: "org",
+				Pricing:      DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{Repository: "org/repo1", Workflows: workflowBillableTimes},
+					{
+						Repository: "org/repo2",
+						Workflows: WorkflowBillableTimes{
+							{
+								ID:           3,
+								Name:         "Workflow1",
+								BillableTime: BillableTime{Ubuntu: 10, Macos: 5},
+							},
+						},
+					},
+				},
+			},
+			want: wantOrg,
+		},
+		{
+			name: "repositories",
+			r: Report{
+				Pricing: DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{Repository: "owner/repo1", Workflows: workflowBillableTimes},
+					{
+						Repository: "owner/repo2",
+						Workflows: WorkflowBillableTimes{
+							{
+								ID:           3,
+								Name:         "Workflow1",
+								BillableTime: BillableTime{Ubuntu: 10, Macos: 5},
+							},
+						},
+					},
+				},
+			},
+			want: wantRepositories,
+		},
+		{
+			name: "duplicate names",
+			r: Report{
+				Pricing: DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{
+						Repository: "owner/repo",
+						Workflows: WorkflowBillableTimes{
+							{
+								ID:           1,
+								Name:         "CI",
+								Path:         ".github/workflows/ci.yml",
+								BillableTime: BillableTime{Ubuntu: 10},
+							},
+							{
+								ID:           2,
+								Name:         "Release",
+								Path:         ".github/workflows/release.yml",
+								BillableTime: BillableTime{Ubuntu: 1},
+							},
+							{
+								ID:           3,
+								Name:         "CI",
+								Path:         ".github/workflows/ci-copy.yml",
+								BillableTime: BillableTime{Ubuntu: 5},
+							},
+						},
+					},
+				},
+			},
+			want: wantDuplicate,
+		},
+		{
+			name: "delta",
+			r: Report{
+				Pricing: DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{Repository: "owner/repo", Workflows: workflowBillableTimes},
+				},
+				Previous: &Snapshot{
+					Repositories: []SnapshotRepository{
+						{
+							Repository: "owner/repo",
+							Workflows: []SnapshotWorkflow{
+								{ID: 1, Name: "Workflow1", Ubuntu: 100, Windows: 90},
+								{ID: 4, Name: "Deleted", Ubuntu: 50},
+							},
+						},
+					},
+				},
+			},
+			want: wantDelta,
+		},
+		{
+			name: "billing summary",
+			r: Report{
+				Pricing: DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{Repository: "owner/repo", Workflows: workflowBillableTimes},
+				},
+				Billing: &BillingSummary{
+					Account:          BillingAccount{Type: AccountUser, Name: "owner"},
+					TotalMinutesUsed: 1200,
+					IncludedMinutes:  2000,
+				},
+			},
+			want: wantBilling,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.r.render(FormatMarkdown, defaultTemplate)
+			if err != nil {
+				t.Fatalf("Report.render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Report.render() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_createOrganizationReport(t *testing.T) {
+	workflows := map[string][]*github.Workflow{
+		"repo1": {
+			{
+				Name: github.String("workflow1"),
+				ID:   github.Int64(2),
+			},
+		},
+		"repo2": {},
+	}
+	client := github.NewClient(mock.NewMockedHTTPClient(append(
+		mockRepositoryOptions(workflows, nil),
+		mock.WithRequestMatch(
+			mock.GetOrgsReposByOrg,
+			[]*github.Repository{
+				{Name: github.String("repo1")},
+				{Name: github.String("repo2")},
+			},
+		),
+	)...))
+	want := Report{
+		Organization: "org",
+		Repositories: []RepositoryBillableTime{
+			{
+				Repository: "org/repo1",
+				Workflows: WorkflowBillableTimes{
+					{
+						ID:           2,
+						Name:         "workflow1",
+						BillableTime: BillableTime{Ubuntu: 2, UbuntuMS: 120000},
+					},
+				},
+			},
+		},
+	}
+
+	got, err := createOrganizationReport(context.Background(), client, Options{Organization: "org"})
+	if err != nil {
+		t.Fatalf("createOrganizationReport() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("createOrganizationReport() = %v, want %v", got, want)
+	}
+}
+
+func Test_createRepositoriesReport(t *testing.T) {
+	client := github.NewClient(mock.NewMockedHTTPClient(mockRepositoryOptions(map[string][]*github.Workflow{
+		"app":   {{ID: github.Int64(1), Name: github.String("CI")}},
+		"lib":   {{ID: github.Int64(2), Name: github.String("Test")}},
+		"empty": {},
+	}, nil)...))
+	want := Report{
+		Repositories: []RepositoryBillableTime{
+			{Repository: "owner/app", Workflows: WorkflowBillableTimes{{ID: 1, Name: "CI", BillableTime: BillableTime{Ubuntu: 1, UbuntuMS: 60000}}}},
+			{Repository: "owner/empty"},
+			{Repository: "other/lib", Workflows: WorkflowBillableTimes{{ID: 2, Name: "Test", BillableTime: BillableTime{Ubuntu: 2, UbuntuMS: 120000}}}},
+		},
+	}
+
+	got, err := createRepositoriesReport(context.Background(), client, Options{Repositories: []string{"owner/app", "other/lib", "owner/empty"}})
+	if err != nil {
+		t.Fatalf("createRepositoriesReport() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("createRepositoriesReport() = %v, want %v", got, want)
+	}
+
+	if _, err := createRepositoriesReport(context.Background(), client, Options{Repositories: []string{"owner/app", "lib"}}); err == nil {
+		t.Error("createRepositoriesReport() error = nil, want an error for an invalid repository")
+	}
+}
+
+func Test_createOrganizationReport_enterprise(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	responses := map[string]string{
+		"/orgs/org/repos":                            `[{"name": "repo"}]`,
+		"/repos/org/repo/actions/workflows":          `{"total_count": 1, "workflows": [{"id": 1, "name": "CI", "path": ".github/workflows/ci.yml"}]}`,
+		"/repos/org/repo/actions/workflows/1/timing": `{"billable": {"UBUNTU": {"total_ms": 120000}}}`,
+		"/repos/org/repo/actions/runs":               `{"total_count": 1, "workflow_runs": [{"id": 10, "workflow_id": 1, "name": "CI"}]}`,
+		"/repos/org/repo/actions/runs/10/jobs":       `{"total_count": 1, "jobs": [{"id": 100, "name": "build"}]}`,
+		"/repos/org/repo/actions/runs/10/timing":     `{"billable": {"UBUNTU": {"total_ms": 90000, "jobs": 1, "job_runs": [{"job_id": 100, "duration_ms": 90000}]}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		path, ok := strings.CutPrefix(r.URL.Path, "/api/v3")
+		body, found := responses[path]
+		if !ok || !found {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			mock.WriteError(rw, http.StatusNotFound, "not found")
+			return
+		}
+		_, _ = rw.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	opts := Options{Organization: "org", TopJobs: 1, APIURL: server.URL + "/api/v3", BillingCycleDay: 1}
+	client, err := createGitHubClient(opts)
+	if err != nil {
+		t.Fatalf("createGitHubClient() error = %v", err)
+	}
+	want := Report{
+		Organization: "org",
+		Repositories: []RepositoryBillableTime{
+			{
+				Repository: "org/repo",
+				Workflows: WorkflowBillableTimes{
+					{
+						ID:           1,
+						Name:         "CI",
+						Path:         ".github/workflows/ci.yml",
+						BillableTime: BillableTime{Ubuntu: 2, UbuntuMS: 120000},
+					},
+				},
+				Jobs: []JobBillableTime{
+					{WorkflowID: 1, WorkflowName: "CI", Name: "build", OS: "UBUNTU", Runs: 1, Minutes: 2, TotalMS: 90000},
+				},
+			},
+		},
+	}
+
+	got, err := createOrganizationReport(context.Background(), client, opts)
+	if err != nil {
+		t.Fatalf("createOrganizationReport() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("createOrganizationReport() = %v, want %v", got, want)
+	}
+}
+
+func TestFormat_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       Format
+		wantErr bool
+	}{
+		{name: "markdown", f: FormatMarkdown, wantErr: false},
+		{name: "json", f: FormatJSON, wantErr: false},
+		{name: "csv", f: FormatCSV, wantErr: false},
+		{name: "unsupported", f: Format("xml"), wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.w.generateMarkdownReport(); got != tt.want {
-				t.Errorf("WorkflowBillableTime.generateMarkdownText() = %v, want %v", got, tt.want)
+			if err := tt.f.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Format.validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
@@ -0,0 +1,181 @@
+package bills
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Budget represents the thresholds of the billable time and the cost.
+// Minutes of the total and the workflows are weighted minutes, and minutes of each environment are raw minutes.
+type Budget struct {
+	Total     Limit            `json:"total" yaml:"total"`         // Limit for the total of all workflows
+	Ubuntu    Limit            `json:"ubuntu" yaml:"ubuntu"`       // Limit for the Ubuntu environment
+	Windows   Limit            `json:"windows" yaml:"windows"`     // Limit for the Windows environment
+	Macos     Limit            `json:"macos" yaml:"macos"`         // Limit for the Mac environment
+	Workflows map[string]Limit `json:"workflows" yaml:"workflows"` // Limit for each workflow, keyed by workflow name or file path
+}
+
+// Limit represents the thresholds that emit a warning and an error when crossed
+type Limit struct {
+	Warning Threshold `json:"warning" yaml:"warning"` // Thresholds to emit a warning
+	Error   Threshold `json:"error" yaml:"error"`     // Thresholds to emit an error
+}
+
+// Threshold represents the maximum minutes and cost. Zero means no threshold.
+type Threshold struct {
+	Minutes float64 `json:"minutes" yaml:"minutes"` // Maximum minutes
+	Cost    float64 `json:"cost" yaml:"cost"`       // Maximum cost (in USD)
+}
+
+// BudgetViolation represents a threshold crossed by the billable time or the cost
+type BudgetViolation struct {
+	Level     string  // warning or error
+	Scope     string  // What crossed the threshold (e.g. Total, Macos, workflow CI in owner/repo)
+	Metric    string  // minutes or cost
+	Actual    float64 // Actual minutes or cost
+	Threshold float64 // Threshold crossed
+}
+
+// resolveBudget returns opts.Budget if set, or the budget loaded from opts.BudgetFile
+func resolveBudget(opts Options) (Budget, error) {
+	if opts.Budget != nil {
+		return *opts.Budget, nil
+	}
+	return loadBudget(opts.BudgetFile)
+}
+
+// loadBudget loads the budget from the JSON file specified by the filePath.
+// If the filePath is empty, an empty budget without thresholds is returned.
+func loadBudget(filePath string) (Budget, error) {
+	var budget Budget
+	if filePath == "" {
+		return budget, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Budget{}, fmt.Errorf("failed to read budget file %s: %w", filePath, err)
+	}
+	if err := json.Unmarshal(data, &budget); err != nil {
+		return Budget{}, fmt.Errorf("failed to parse budget file %s: %w", filePath, err)
+	}
+	if err := budget.validate(); err != nil {
+		return Budget{}, fmt.Errorf("invalid budget file %s: %w", filePath, err)
+	}
+
+	return budget, nil
+}
+
+// validate returns an error if a threshold is negative
+func (b Budget) validate() error {
+	limits := map[string]Limit{"total": b.Total, "ubuntu": b.Ubuntu, "windows": b.Windows, "macos": b.Macos}
+	for _, scope := range []string{"total", "ubuntu", "windows", "macos"} {
+		if err := limits[scope].validate(); err != nil {
+			return fmt.Errorf("%s.%w", scope, err)
+		}
+	}
+	for workflow, limit := range b.Workflows {
+		if err := limit.validate(); err != nil {
+			return fmt.Errorf("workflows.%s.%w", workflow, err)
+		}
+	}
+	return nil
+}
+
+// validate returns an error if a threshold of the limit is negative
+func (l Limit) validate() error {
+	thresholds := map[string]Threshold{"warning": l.Warning, "error": l.Error}
+	for _, level := range []string{"warning", "error"} {
+		if thresholds[level].Minutes < 0 {
+			return fmt.Errorf("%s.minutes must not be negative", level)
+		}
+		if thresholds[level].Cost < 0 {
+			return fmt.Errorf("%s.cost must not be negative", level)
+		}
+	}
+	return nil
+}
+
+// evaluate evaluates the budget against the report and returns the thresholds crossed.
+// An error is reported instead of a warning when both thresholds are crossed.
+func (b Budget) evaluate(r Report) []BudgetViolation {
+	var violations []BudgetViolation
+
+	total := r.calculateTotal()
+	violations = append(violations, b.Total.evaluate("Total", r.Pricing.weightedMinutes(total), r.Pricing.cost(total))...)
+	violations = append(violations, b.Ubuntu.evaluate("Ubuntu", float64(total.Ubuntu), float64(total.Ubuntu)*r.Pricing.Ubuntu.PricePerMinute)...)
+	violations = append(violations, b.Windows.evaluate("Windows", float64(total.Windows), float64(total.Windows)*r.Pricing.Windows.PricePerMinute)...)
+	violations = append(violations, b.Macos.evaluate("Macos", float64(total.Macos), float64(total.Macos)*r.Pricing.Macos.PricePerMinute)...)
+
+	keys := make([]string, 0, len(b.Workflows))
+	for key := range b.Workflows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, rbt := range r.Repositories {
+		for _, wbt := range rbt.Workflows.sortWorkflows() {
+			for _, key := range keys {
+				if key != wbt.Name && key != wbt.Path {
+					continue
+				}
+				scope := fmt.Sprintf("Workflow %s in %s", rbt.Workflows.displayName(wbt), rbt.Repository)
+				violations = append(violations, b.Workflows[key].evaluate(scope, r.Pricing.weightedMinutes(wbt.BillableTime), r.Pricing.cost(wbt.BillableTime))...)
+			}
+		}
+	}
+
+	return violations
+}
+
+// evaluate returns the thresholds of the limit crossed by the minutes and the cost
+func (l Limit) evaluate(scope string, minutes, cost float64) []BudgetViolation {
+	var violations []BudgetViolation
+	for _, metric := range []struct {
+		name             string
+		actual           float64
+		warning, errorAt float64
+	}{
+		{name: "minutes", actual: minutes, warning: l.Warning.Minutes, errorAt: l.Error.Minutes},
+		{name: "cost", actual: cost, warning: l.Warning.Cost, errorAt: l.Error.Cost},
+	} {
+		switch {
+		case metric.errorAt > 0 && metric.actual > metric.errorAt:
+			violations = append(violations, BudgetViolation{Level: "error", Scope: scope, Metric: metric.name, Actual: metric.actual, Threshold: metric.errorAt})
+		case metric.warning > 0 && metric.actual > metric.warning:
+			violations = append(violations, BudgetViolation{Level: "warning", Scope: scope, Metric: metric.name, Actual: metric.actual, Threshold: metric.warning})
+		}
+	}
+	return violations
+}
+
+// hasBudgetError returns true if any of the violations is an error
+func hasBudgetError(violations []BudgetViolation) bool {
+	for _, v := range violations {
+		if v.Level == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// message returns a human-readable message of the violation
+func (v BudgetViolation) message() string {
+	if v.Metric == "cost" {
+		return fmt.Sprintf("%s cost %s exceeds the budget of %s", v.Scope, formatCost(v.Actual), formatCost(v.Threshold))
+	}
+	return fmt.Sprintf("%s %s minutes exceeds the budget of %s minutes", v.Scope, formatMinutes(v.Actual), formatMinutes(v.Threshold))
+}
+
+// formatWorkflowCommand formats the violation as a GitHub Actions workflow command (e.g. ::warning title=...::message)
+func (v BudgetViolation) formatWorkflowCommand() string {
+	return fmt.Sprintf("::%s title=actbills budget::%s\n", v.Level, escapeWorkflowCommand(v.message()))
+}
+
+// escapeWorkflowCommand escapes the data of a workflow command
+func escapeWorkflowCommand(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
@@ -0,0 +1,176 @@
+package bills
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_loadBudget(t *testing.T) {
+	tempDir := t.TempDir()
+	validPath := filepath.Join(tempDir, "budget.json")
+	err := os.WriteFile(validPath, []byte(`{"total": {"warning": {"cost": 15}, "error": {"cost": 20}}, "workflows": {"CI": {"error": {"minutes": 500}}}}`), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	invalidPath := filepath.Join(tempDir, "invalid.json")
+	err = os.WriteFile(invalidPath, []byte(`{"total": {"warning": {"cost": "15"}}}`), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	negativePath := filepath.Join(tempDir, "negative.json")
+	err = os.WriteFile(negativePath, []byte(`{"workflows": {"CI": {"warning": {"minutes": -1}}}}`), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filePath string
+		want     Budget
+		wantErr  bool
+	}{
+		{
+			name:     "empty path",
+			filePath: "",
+			want:     Budget{},
+			wantErr:  false,
+		},
+		{
+			name:     "valid",
+			filePath: validPath,
+			want: Budget{
+				Total: Limit{Warning: Threshold{Cost: 15}, Error: Threshold{Cost: 20}},
+				Workflows: map[string]Limit{
+					"CI": {Error: Threshold{Minutes: 500}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "invalid",
+			filePath: invalidPath,
+			want:     Budget{},
+			wantErr:  true,
+		},
+		{
+			name:     "negative threshold",
+			filePath: negativePath,
+			want:     Budget{},
+			wantErr:  true,
+		},
+		{
+			name:     "not found",
+			filePath: filepath.Join(tempDir, "notfound.json"),
+			want:     Budget{},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadBudget(tt.filePath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadBudget() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadBudget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudget_evaluate(t *testing.T) {
+	report := Report{
+		Pricing: DefaultPricing,
+		Repositories: []RepositoryBillableTime{
+			{
+				Repository: "owner/repo",
+				Workflows: WorkflowBillableTimes{
+					{ID: 1, Name: "CI", Path: ".github/workflows/ci.yml", BillableTime: BillableTime{Ubuntu: 300, Macos: 60}},
+					{ID: 2, Name: "Release", Path: ".github/workflows/release.yml", BillableTime: BillableTime{Windows: 100}},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name          string
+		budget        Budget
+		want          []BudgetViolation
+		wantHasErrors bool
+	}{
+		{
+			name:   "no thresholds",
+			budget: Budget{},
+			want:   nil,
+		},
+		{
+			name: "not crossed",
+			budget: Budget{
+				Total: Limit{Warning: Threshold{Minutes: 2000, Cost: 10}},
+			},
+			want: nil,
+		},
+		{
+			name: "crossed",
+			budget: Budget{
+				Total: Limit{Warning: Threshold{Cost: 5}, Error: Threshold{Cost: 7}},
+				Macos: Limit{Warning: Threshold{Minutes: 50}},
+				Workflows: map[string]Limit{
+					".github/workflows/release.yml": {Error: Threshold{Minutes: 150}},
+				},
+			},
+			want: []BudgetViolation{
+				{Level: "error", Scope: "Total", Metric: "cost", Actual: 8.8, Threshold: 7},
+				{Level: "warning", Scope: "Macos", Metric: "minutes", Actual: 60, Threshold: 50},
+				{Level: "error", Scope: "Workflow Release in owner/repo", Metric: "minutes", Actual: 200, Threshold: 150},
+			},
+			wantHasErrors: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.budget.evaluate(report)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Budget.evaluate() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				// compare the cost with a tolerance for floating point errors
+				if got[i].Level != tt.want[i].Level || got[i].Scope != tt.want[i].Scope || got[i].Metric != tt.want[i].Metric ||
+					got[i].Threshold != tt.want[i].Threshold || got[i].Actual-tt.want[i].Actual > 1e-9 || tt.want[i].Actual-got[i].Actual > 1e-9 {
+					t.Errorf("Budget.evaluate()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+			if hasBudgetError(got) != tt.wantHasErrors {
+				t.Errorf("hasBudgetError() = %v, want %v", hasBudgetError(got), tt.wantHasErrors)
+			}
+		})
+	}
+}
+
+func TestBudgetViolation_formatWorkflowCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		v    BudgetViolation
+		want string
+	}{
+		{
+			name: "cost",
+			v:    BudgetViolation{Level: "error", Scope: "Total", Metric: "cost", Actual: 9.2, Threshold: 7},
+			want: "::error title=actbills budget::Total cost $9.20 exceeds the budget of $7.00\n",
+		},
+		{
+			name: "minutes",
+			v:    BudgetViolation{Level: "warning", Scope: "Workflow 100% CI in owner/repo", Metric: "minutes", Actual: 60, Threshold: 50},
+			want: "::warning title=actbills budget::Workflow 100%25 CI in owner/repo 60 minutes exceeds the budget of 50 minutes\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.formatWorkflowCommand(); got != tt.want {
+				t.Errorf("BudgetViolation.formatWorkflowCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package bills
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the path of the configuration file loaded if it exists and no other path is given
+const DefaultConfigFile = ".actbills.yaml"
+
+// Config represents the YAML configuration file.
+// The keys not set keep the values of the flags, and the flags given on the command line take precedence over the keys.
+// The notification URLs are left out as they are secrets.
+type Config struct {
+	Repository   string   `yaml:"repository"`   // Repository name in owner/repo format
+	Organization string   `yaml:"organization"` // Organization name. If set, all private repositories of the organization are reported
+	Repositories []string `yaml:"repositories"` // Repositories in owner/repo format reported together, instead of repository or organization
+
+	Format     Format    `yaml:"format"`      // Output format
+	Output     string    `yaml:"output"`      // Path to write the report to
+	JSONReport string    `yaml:"json_report"` // Path to write the JSON report to in addition to the report in the output format
+	Template   string    `yaml:"template"`    // Path to a Go text/template file rendering the markdown report
+	Sort       SortKey   `yaml:"sort"`        // Key to sort the workflow tables by
+	SortOrder  SortOrder `yaml:"sort_order"`  // Order to sort the workflow tables in
+	Top        *int      `yaml:"top"`         // Number of workflows listed in each workflow table
+	TopJobs    *int      `yaml:"top_jobs"`    // Number of jobs to list by billable time
+
+	Filter WorkflowFilter `yaml:"filter"` // Conditions to include and exclude workflows from the report
+
+	LargerRunners *bool             `yaml:"larger_runners"` // Aggregate the billable time of the jobs run on larger runners
+	RunnerSKUs    map[string]string `yaml:"runner_skus"`    // Map of runner labels or runner group names to larger runner SKUs
+	Storage       *bool             `yaml:"storage"`        // List the artifacts and the cache usage of each repository
+
+	Pricing      *Pricing `yaml:"pricing"`        // Pricing overriding the default pricing, in the same keys as the pricing file
+	Budget       *Budget  `yaml:"budget"`         // Budget thresholds, in the same keys as the budget file
+	FailOnBudget *bool    `yaml:"fail_on_budget"` // Fail when an error threshold of the budget is crossed
+
+	Concurrency   *int  `yaml:"concurrency"`     // Maximum number of requests sent to the GitHub API concurrently
+	CollectErrors *bool `yaml:"collect_errors"`  // Report all failed requests instead of stopping at the first error
+	Tolerant      *bool `yaml:"tolerant"`        // Render the report without the workflows that could not be fetched
+	FailOnPartial *bool `yaml:"fail_on_partial"` // Fail when some workflows could not be fetched in tolerant mode
+
+	MaxRetries       *int           `yaml:"max_retries"`         // Maximum number of retries of a failed request
+	MaxRateLimitWait *time.Duration `yaml:"max_rate_limit_wait"` // Maximum time to wait for a rate limit to reset (e.g. 15m)
+
+	APIURL    string `yaml:"api_url"`    // GitHub API URL
+	UploadURL string `yaml:"upload_url"` // GitHub upload URL
+
+	AppID             *int64 `yaml:"app_id"`               // GitHub App ID
+	AppPrivateKeyFile string `yaml:"app_private_key_file"` // Path to the PEM private key of the GitHub App
+
+	Snapshot     string `yaml:"snapshot"`      // Path to a JSON file keeping the snapshots of the previous runs
+	SnapshotKeep *int   `yaml:"snapshot_keep"` // Number of snapshots kept in the snapshot file
+
+	BillingCycleDay *int   `yaml:"billing_cycle_day"` // Day of the month the billing cycle starts on (1-28)
+	Forecast        *bool  `yaml:"forecast"`          // Project the billable time to the end of the billing cycle
+	Plan            string `yaml:"plan"`              // GitHub plan to compare the forecast with the included minutes
+
+	BillingSummary *bool `yaml:"billing_summary"` // Render the Actions billing summary above the workflow tables
+
+	CommentOn         *int   `yaml:"comment_on"`         // Number of the issue or pull request to post the markdown report to
+	TrackingIssue     *bool  `yaml:"tracking_issue"`     // Open a tracking issue with the markdown report for each billing cycle
+	PublishRepository string `yaml:"publish_repository"` // Repository in owner/repo format to post the comment and the tracking issue to
+
+	NotifyDryRun *bool `yaml:"notify_dry_run"` // Print the payloads of the notifications instead of sending them
+}
+
+// LoadConfig loads the configuration from the YAML file specified by the filePath.
+// If the filePath is empty, DefaultConfigFile is loaded if it exists, and an empty configuration is returned otherwise.
+// Unknown keys, values of a wrong type and invalid values are reported with the path of the key (e.g. filter.include_states).
+func LoadConfig(filePath string) (Config, error) {
+	var config Config
+	explicit := filePath != ""
+	if !explicit {
+		filePath = DefaultConfigFile
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return config, nil
+		}
+		return Config{}, fmt.Errorf("failed to read config file %s: %w", filePath, err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config file %s: %w", filePath, err)
+	}
+	// an empty file has no content
+	if len(document.Content) > 0 {
+		if err := decodeConfigNode(document.Content[0], reflect.ValueOf(&config).Elem(), ""); err != nil {
+			return Config{}, fmt.Errorf("invalid config file %s: %w", filePath, err)
+		}
+	}
+	if err := config.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config file %s: %w", filePath, err)
+	}
+
+	return config, nil
+}
+
+// configDefaulter is implemented by the values of the configuration file that override defaults instead of zero values
+type configDefaulter interface {
+	setDefaults()
+}
+
+// decodeConfigNode decodes the YAML node into v by the yaml tags of the struct fields.
+// Unlike yaml.Node.Decode, the errors of unknown keys and values of a wrong type name the path of the key.
+// Null values are skipped, so they keep the key unset.
+func decodeConfigNode(node *yaml.Node, v reflect.Value, keyPath string) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+			if d, ok := v.Interface().(configDefaulter); ok {
+				d.setDefaults()
+			}
+		}
+		return decodeConfigNode(node, v.Elem(), keyPath)
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return configTypeError(node, v.Type(), keyPath)
+		}
+		fields := make(map[string]reflect.Value, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			if name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ","); name != "" && name != "-" {
+				fields[name] = v.Field(i)
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			field, ok := fields[key.Value]
+			if !ok {
+				return fmt.Errorf("%s: unknown key (line %d)", joinKeyPath(keyPath, key.Value), key.Line)
+			}
+			if err := decodeConfigNode(node.Content[i+1], field, joinKeyPath(keyPath, key.Value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return configTypeError(node, v.Type(), keyPath)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeConfigNode(node.Content[i+1], elem, joinKeyPath(keyPath, key)); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		return nil
+	default:
+		if err := node.Decode(v.Addr().Interface()); err != nil {
+			return configTypeError(node, v.Type(), keyPath)
+		}
+		return nil
+	}
+}
+
+// configTypeError returns the error of a value not decodable into the type of the key
+func configTypeError(node *yaml.Node, t reflect.Type, keyPath string) error {
+	var expected string
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		expected = "a duration (e.g. 15m)"
+	case t.Kind() == reflect.Bool:
+		expected = "a boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		expected = "an integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		expected = "a number"
+	case t.Kind() == reflect.String:
+		expected = "a string"
+	case t.Kind() == reflect.Slice:
+		expected = "a list"
+	default:
+		expected = "a mapping"
+	}
+	if node.Kind == yaml.ScalarNode {
+		return fmt.Errorf("%s: %q must be %s (line %d)", keyPath, node.Value, expected, node.Line)
+	}
+	return fmt.Errorf("%s: must be %s (line %d)", keyPath, expected, node.Line)
+}
+
+// joinKeyPath returns the path of the key under the parent key path
+func joinKeyPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// validate returns an error naming the key if a value is not supported
+func (c Config) validate() error {
+	if c.Repository != "" && c.Organization != "" {
+		return errors.New("organization: must not be set together with repository")
+	}
+	if len(c.Repositories) > 0 && (c.Repository != "" || c.Organization != "") {
+		return errors.New("repositories: must not be set together with repository or organization")
+	}
+	if err := validateRepositories(c.Repositories); err != nil {
+		return fmt.Errorf("repositories%w", err)
+	}
+	if c.Format != "" {
+		if err := c.Format.validate(); err != nil {
+			return fmt.Errorf("format: %w", err)
+		}
+	}
+	if _, err := (WorkflowSort{Key: c.Sort}).normalize(); err != nil {
+		return fmt.Errorf("sort: %w", err)
+	}
+	if _, err := (WorkflowSort{Order: c.SortOrder}).normalize(); err != nil {
+		return fmt.Errorf("sort_order: %w", err)
+	}
+	if c.Top != nil && *c.Top < 0 {
+		return fmt.Errorf("top: must not be negative: %d", *c.Top)
+	}
+	if c.TopJobs != nil && *c.TopJobs < 0 {
+		return fmt.Errorf("top_jobs: must not be negative: %d", *c.TopJobs)
+	}
+
+	filters := []struct {
+		key    string
+		filter WorkflowFilter
+	}{
+		{"include_names", WorkflowFilter{IncludeNames: c.Filter.IncludeNames}},
+		{"exclude_names", WorkflowFilter{ExcludeNames: c.Filter.ExcludeNames}},
+		{"include_paths", WorkflowFilter{IncludePaths: c.Filter.IncludePaths}},
+		{"exclude_paths", WorkflowFilter{ExcludePaths: c.Filter.ExcludePaths}},
+		{"include_states", WorkflowFilter{IncludeStates: c.Filter.IncludeStates}},
+		{"exclude_states", WorkflowFilter{ExcludeStates: c.Filter.ExcludeStates}},
+	}
+	for _, f := range filters {
+		if err := f.filter.validate(); err != nil {
+			return fmt.Errorf("filter.%s: %w", f.key, err)
+		}
+	}
+
+	if c.Pricing != nil {
+		if err := c.Pricing.validate(); err != nil {
+			return fmt.Errorf("pricing.%w", err)
+		}
+	}
+	if c.Budget != nil {
+		if err := c.Budget.validate(); err != nil {
+			return fmt.Errorf("budget.%w", err)
+		}
+	}
+	if c.BillingCycleDay != nil && (*c.BillingCycleDay < 1 || *c.BillingCycleDay > 28) {
+		return fmt.Errorf("billing_cycle_day: must be between 1 and 28: %d", *c.BillingCycleDay)
+	}
+	if _, ok := Plans[c.Plan]; c.Plan != "" && !ok {
+		return fmt.Errorf("plan: unsupported plan: %s", c.Plan)
+	}
+	if c.CommentOn != nil && *c.CommentOn < 0 {
+		return fmt.Errorf("comment_on: invalid issue or pull request number: %d", *c.CommentOn)
+	}
+	return nil
+}
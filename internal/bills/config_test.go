@@ -0,0 +1,233 @@
+package bills
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		return path
+	}
+	intPtr := func(i int) *int { return &i }
+	boolPtr := func(b bool) *bool { return &b }
+	wait := 5 * time.Minute
+
+	tests := []struct {
+		name     string
+		filePath string
+		want     Config
+		wantErr  string
+	}{
+		{
+			name: "full",
+			filePath: writeFile("full.yaml", `
+organization: your-org
+format: json
+output: report.json
+sort: cost
+top: 10
+filter:
+  exclude_names: [zzz_actbills]
+  exclude_paths:
+    - dynamic/*/*
+runner_skus:
+  big-runner: linux-8-core
+budget:
+  total:
+    warning:
+      cost: 100
+  workflows:
+    CI:
+      error:
+        minutes: 1000
+tolerant: true
+max_retries: 0
+max_rate_limit_wait: 5m
+billing_cycle_day: 15
+plan: team
+`),
+			want: Config{
+				Organization: "your-org",
+				Format:       FormatJSON,
+				Output:       "report.json",
+				Sort:         SortByCost,
+				Top:          intPtr(10),
+				Filter:       WorkflowFilter{ExcludeNames: []string{"zzz_actbills"}, ExcludePaths: []string{"dynamic/*/*"}},
+				RunnerSKUs:   map[string]string{"big-runner": "linux-8-core"},
+				Budget: &Budget{
+					Total:     Limit{Warning: Threshold{Cost: 100}},
+					Workflows: map[string]Limit{"CI": {Error: Threshold{Minutes: 1000}}},
+				},
+				Tolerant:         boolPtr(true),
+				MaxRetries:       intPtr(0),
+				MaxRateLimitWait: &wait,
+				BillingCycleDay:  intPtr(15),
+				Plan:             "team",
+			},
+		},
+		{
+			name:     "pricing overrides the default pricing",
+			filePath: writeFile("pricing.yaml", "pricing:\n  macos:\n    price_per_minute: 0.07\n  larger_runners:\n    linux-2-core-arm: 0.005\n"),
+			want: Config{
+				Pricing: &Pricing{
+					Ubuntu:        DefaultPricing.Ubuntu,
+					Windows:       DefaultPricing.Windows,
+					Macos:         Rate{Multiplier: 10, PricePerMinute: 0.07},
+					LargerRunners: withLargerRunnerPrices(map[string]float64{"linux-2-core-arm": 0.005}),
+
+					StoragePerGBMonth: DefaultPricing.StoragePerGBMonth,
+				},
+			},
+		},
+		{
+			name:     "repositories",
+			filePath: writeFile("repositories.yaml", "repositories:\n  - owner/app\n  - other/lib\n"),
+			want:     Config{Repositories: []string{"owner/app", "other/lib"}},
+		},
+		{
+			name:     "null keeps the key unset",
+			filePath: writeFile("null.yaml", "top:\nforecast: ~\n"),
+			want:     Config{},
+		},
+		{
+			name:     "empty",
+			filePath: writeFile("empty.yaml", ""),
+			want:     Config{},
+		},
+		{
+			name:     "unknown key",
+			filePath: writeFile("unknown.yaml", "filter:\n  include_state: [active]\n"),
+			wantErr:  "filter.include_state: unknown key (line 2)",
+		},
+		{
+			name:     "wrong type",
+			filePath: writeFile("type.yaml", "top: ten\n"),
+			wantErr:  `top: "ten" must be an integer (line 1)`,
+		},
+		{
+			name:     "wrong type in a map",
+			filePath: writeFile("map.yaml", "budget:\n  workflows:\n    CI:\n      error: 100\n"),
+			wantErr:  `budget.workflows.CI.error: "100" must be a mapping (line 4)`,
+		},
+		{
+			name:     "list expected",
+			filePath: writeFile("list.yaml", "filter:\n  exclude_names: zzz_actbills\n"),
+			wantErr:  `filter.exclude_names: "zzz_actbills" must be a list (line 2)`,
+		},
+		{
+			name:     "unsupported state",
+			filePath: writeFile("state.yaml", "filter:\n  include_states: [enabled]\n"),
+			wantErr:  `filter.include_states: unsupported workflow state "enabled"`,
+		},
+		{
+			name:     "unsupported format",
+			filePath: writeFile("format.yaml", "format: html\n"),
+			wantErr:  "format: unsupported format: html",
+		},
+		{
+			name:     "negative price",
+			filePath: writeFile("price.yaml", "pricing:\n  windows:\n    multiplier: -1\n"),
+			wantErr:  "pricing.windows.multiplier must not be negative",
+		},
+		{
+			name:     "negative threshold",
+			filePath: writeFile("threshold.yaml", "budget:\n  macos:\n    warning:\n      minutes: -1\n"),
+			wantErr:  "budget.macos.warning.minutes must not be negative",
+		},
+		{
+			name:     "billing cycle day out of range",
+			filePath: writeFile("day.yaml", "billing_cycle_day: 31\n"),
+			wantErr:  "billing_cycle_day: must be between 1 and 28",
+		},
+		{
+			name:     "repository and organization",
+			filePath: writeFile("owner.yaml", "repository: owner/repo\norganization: your-org\n"),
+			wantErr:  "organization: must not be set together with repository",
+		},
+		{
+			name:     "repositories and organization",
+			filePath: writeFile("repositories-owner.yaml", "repositories: [owner/app]\norganization: your-org\n"),
+			wantErr:  "repositories: must not be set together with repository or organization",
+		},
+		{
+			name:     "invalid repository in repositories",
+			filePath: writeFile("repositories-format.yaml", "repositories: [owner/app, lib]\n"),
+			wantErr:  "repositories[1]: invalid repository name format: lib",
+		},
+		{
+			name:     "duplicate repository in repositories",
+			filePath: writeFile("repositories-duplicate.yaml", "repositories: [owner/app, owner/lib, owner/app]\n"),
+			wantErr:  "repositories[2]: duplicate repository: owner/app",
+		},
+		{
+			name:     "invalid yaml",
+			filePath: writeFile("invalid.yaml", "top: [10\n"),
+			wantErr:  "failed to parse config file",
+		},
+		{
+			name:     "not found",
+			filePath: filepath.Join(tempDir, "notfound.yaml"),
+			wantErr:  "failed to read config file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadConfig(tt.filePath)
+			if err != nil || tt.wantErr != "" {
+				if err == nil || tt.wantErr == "" || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if DefaultPricing.Macos.PricePerMinute != 0.08 || DefaultPricing.LargerRunners["linux-2-core-arm"] != 0 {
+		t.Errorf("LoadConfig() modified DefaultPricing")
+	}
+}
+
+func TestLoadConfig_default(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	// the default file is optional
+	got, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, Config{}) {
+		t.Errorf("LoadConfig() = %+v, want empty", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, DefaultConfigFile), []byte("repository: owner/repo\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	got, err = LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got.Repository != "owner/repo" {
+		t.Errorf("LoadConfig() repository = %s, want owner/repo", got.Repository)
+	}
+}
@@ -0,0 +1,62 @@
+package bills
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+// csvHeader is the header row of the CSV report
+var csvHeader = []string{"Workflow ID", "Workflow", "Path", "State", "Ubuntu (min)", "Windows (min)", "Macos (min)", "Weighted (min)", "Cost (USD)"}
+
+// generateCSVReport generates a CSV-formatted report based on the provided Report data.
+// It includes a header row and one row for each workflow.
+// For a report of several repositories, a Repository column is added as the first column.
+// Fields are quoted and records end with CRLF as described in RFC 4180.
+func (r Report) generateCSVReport() (string, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+	writer.UseCRLF = true
+
+	header := csvHeader
+	if r.multiRepository() {
+		header = append([]string{"Repository"}, csvHeader...)
+	}
+	if err := writer.Write(header); err != nil {
+		return "", err
+	}
+
+	for _, rbt := range r.Repositories {
+		for _, wbt := range rbt.Workflows.sortWorkflows() {
+			record := wbt.csvRecord(r.Pricing)
+			if r.multiRepository() {
+				record = append([]string{rbt.Repository}, record...)
+			}
+			if err := writer.Write(record); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// csvRecord formats the workflow and its billable time as a CSV record
+func (wbt WorkflowBillableTime) csvRecord(pricing Pricing) []string {
+	return []string{
+		strconv.FormatInt(wbt.ID, 10),
+		wbt.Name,
+		wbt.Path,
+		wbt.State,
+		strconv.FormatInt(wbt.Ubuntu, 10),
+		strconv.FormatInt(wbt.Windows, 10),
+		strconv.FormatInt(wbt.Macos, 10),
+		formatMinutes(pricing.weightedMinutes(wbt.BillableTime)),
+		strconv.FormatFloat(pricing.cost(wbt.BillableTime), 'f', 2, 64),
+	}
+}
@@ -0,0 +1,100 @@
+package bills
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReport_generateCSVReport(t *testing.T) {
+	workflows := WorkflowBillableTimes{
+		{
+			ID:           2,
+			Name:         `Build "nightly", all`,
+			Path:         ".github/workflows/nightly.yml",
+			State:        "active",
+			BillableTime: BillableTime{Ubuntu: 180, Windows: 30},
+		},
+		{
+			ID:           1,
+			Name:         "Build",
+			Path:         ".github/workflows/build.yml",
+			State:        "disabled_manually",
+			BillableTime: BillableTime{Ubuntu: 120, Windows: 90, Macos: 60},
+		},
+	}
+	tests := []struct {
+		name    string
+		r       Report
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "basic",
+			r: Report{
+				Pricing: DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{Repository: "owner/repo", Workflows: workflows},
+				},
+			},
+			want: strings.ReplaceAll(`Workflow ID,Workflow,Path,State,Ubuntu (min),Windows (min),Macos (min),Weighted (min),Cost (USD)
+1,Build,.github/workflows/build.yml,disabled_manually,120,90,60,900,7.20
+2,"Build ""nightly"", all",.github/workflows/nightly.yml,active,180,30,0,240,1.92
+`, "\n", "\r\n"),
+			wantErr: false,
+		},
+		{
+			name: "organization",
+			r: Report{
+				Organization: "org",
+				Pricing:      DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{Repository: "org/repo1", Workflows: workflows},
+					{
+						Repository: "org/repo2",
+						Workflows: WorkflowBillableTimes{
+							{
+								ID:           3,
+								Name:         "Build",
+								Path:         ".github/workflows/build.yml",
+								State:        "active",
+								BillableTime: BillableTime{Macos: 5},
+							},
+						},
+					},
+				},
+			},
+			want: strings.ReplaceAll(`Repository,Workflow ID,Workflow,Path,State,Ubuntu (min),Windows (min),Macos (min),Weighted (min),Cost (USD)
+org/repo1,1,Build,.github/workflows/build.yml,disabled_manually,120,90,60,900,7.20
+org/repo1,2,"Build ""nightly"", all",.github/workflows/nightly.yml,active,180,30,0,240,1.92
+org/repo2,3,Build,.github/workflows/build.yml,active,0,0,5,50,0.40
+`, "\n", "\r\n"),
+			wantErr: false,
+		},
+		{
+			name: "repositories",
+			r: Report{
+				Pricing: DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{Repository: "owner/app", Workflows: workflows[1:]},
+					{Repository: "other/lib"},
+				},
+			},
+			want: strings.ReplaceAll(`Repository,Workflow ID,Workflow,Path,State,Ubuntu (min),Windows (min),Macos (min),Weighted (min),Cost (USD)
+owner/app,1,Build,.github/workflows/build.yml,disabled_manually,120,90,60,900,7.20
+`, "\n", "\r\n"),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.r.generateCSVReport()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Report.generateCSVReport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Report.generateCSVReport() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package bills
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const failuresTableHeader = "| Repository | Workflow | Reason |\n| --- | --- | --- |\n"
+
+// FetchFailure represents a workflow or a repository that could not be fetched
+type FetchFailure struct {
+	Repository string // Repository name in owner/repo format
+	WorkflowID int64  // Workflow ID (0 if the failure is not specific to a workflow)
+	Workflow   string // Workflow display name (empty if the failure is not specific to a workflow)
+	Path       string // Workflow file path
+	Reason     string // Error message
+}
+
+// tolerated represents the result of a task with the error tolerated
+type tolerated[R any] struct {
+	value R
+	err   error
+}
+
+// tolerate wraps fn to return its error in the result instead of failing, if tolerant is set.
+// Errors after the context is done are not tolerated, so a cancelled report still fails.
+func tolerate[T, R any](tolerant bool, fn func(context.Context, T) (R, error)) func(context.Context, T) (tolerated[R], error) {
+	return func(ctx context.Context, item T) (tolerated[R], error) {
+		value, err := fn(ctx, item)
+		if err != nil && (!tolerant || ctx.Err() != nil) {
+			return tolerated[R]{}, err
+		}
+		return tolerated[R]{value: value, err: err}, nil
+	}
+}
+
+// target returns the workflow or the repository that could not be fetched
+func (f FetchFailure) target() string {
+	switch {
+	case f.Workflow != "" && f.Path != "":
+		return fmt.Sprintf("%s (%s)", f.Workflow, f.Path)
+	case f.Workflow != "":
+		return f.Workflow
+	default:
+		return "-"
+	}
+}
+
+// generateFailuresMarkdown generates a markdown section with a table of the workflows and repositories that could not be fetched
+func generateFailuresMarkdown(failures []FetchFailure) string {
+	var sb strings.Builder
+	sb.WriteString("\n## Could not fetch\n\n")
+	sb.WriteString("The billable time of the following workflows and repositories is not included in the tables above.\n\n")
+	sb.WriteString(failuresTableHeader)
+	for _, f := range failures {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", f.Repository, escapeMarkdownCell(f.target()), escapeMarkdownCell(f.Reason)))
+	}
+	return sb.String()
+}
+
+// formatWorkflowCommand formats the failure as a GitHub Actions warning workflow command
+func (f FetchFailure) formatWorkflowCommand() string {
+	message := fmt.Sprintf("Could not fetch %s: %s", f.Repository, f.Reason)
+	if f.Workflow != "" {
+		message = fmt.Sprintf("Could not fetch workflow %s in %s: %s", f.target(), f.Repository, f.Reason)
+	}
+	return fmt.Sprintf("::warning title=actbills::%s\n", escapeWorkflowCommand(message))
+}
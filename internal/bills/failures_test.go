@@ -0,0 +1,80 @@
+package bills
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_tolerate(t *testing.T) {
+	errFailed := errors.New("failed")
+	fail := func(context.Context, int) (int, error) { return 0, errFailed }
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		tolerant bool
+		wantErr  error
+		wantRes  error
+	}{
+		{name: "tolerant", ctx: context.Background(), tolerant: true, wantErr: nil, wantRes: errFailed},
+		{name: "not tolerant", ctx: context.Background(), tolerant: false, wantErr: errFailed, wantRes: nil},
+		{name: "cancelled", ctx: cancelled, tolerant: true, wantErr: errFailed, wantRes: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tolerate(tt.tolerant, fail)(tt.ctx, 1)
+			if !errors.Is(err, tt.wantErr) || !errors.Is(got.err, tt.wantRes) {
+				t.Errorf("tolerate() = %v, %v, want %v, %v", got.err, err, tt.wantRes, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_generateFailuresMarkdown(t *testing.T) {
+	failures := []FetchFailure{
+		{Repository: "owner/repo", WorkflowID: 1, Workflow: "CI", Path: ".github/workflows/ci.yml", Reason: "GET https://api.github.com/repos/owner/repo/actions/workflows/1/timing: 404 Not Found []"},
+		{Repository: "owner/other", Reason: "failed to fetch the jobs: a | b\nc"},
+	}
+	want := `
+## Could not fetch
+
+The billable time of the following workflows and repositories is not included in the tables above.
+
+| Repository | Workflow | Reason |
+| --- | --- | --- |
+| owner/repo | CI (.github/workflows/ci.yml) | GET https://api.github.com/repos/owner/repo/actions/workflows/1/timing: 404 Not Found [] |
+| owner/other | - | failed to fetch the jobs: a \| b c |
+`
+	if got := generateFailuresMarkdown(failures); got != want {
+		t.Errorf("generateFailuresMarkdown() = %v, want %v", got, want)
+	}
+}
+
+func TestFetchFailure_formatWorkflowCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		f    FetchFailure
+		want string
+	}{
+		{
+			name: "workflow",
+			f:    FetchFailure{Repository: "owner/repo", WorkflowID: 1, Workflow: "CI", Reason: "404 Not Found"},
+			want: "::warning title=actbills::Could not fetch workflow CI in owner/repo: 404 Not Found\n",
+		},
+		{
+			name: "repository",
+			f:    FetchFailure{Repository: "owner/repo", Reason: "100% failed"},
+			want: "::warning title=actbills::Could not fetch owner/repo: 100%25 failed\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.formatWorkflowCommand(); got != tt.want {
+				t.Errorf("FetchFailure.formatWorkflowCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,11 +5,12 @@ import (
 	"os"
 )
 
-// getOutputPath returns the value of the environment variable GITHUB_STEP_SUMMARY.
-// If the environment variable is not set, it returns "/dev/stdout".
-func getOutputPath() string {
+// getOutputPath returns the value of the environment variable GITHUB_STEP_SUMMARY for the markdown format.
+// If the environment variable is not set, or the format is not markdown, it returns "/dev/stdout",
+// as the job summary only renders markdown.
+func getOutputPath(format Format) string {
 	outputPath := os.Getenv("GITHUB_STEP_SUMMARY")
-	if outputPath == "" {
+	if outputPath == "" || format != FormatMarkdown {
 		outputPath = "/dev/stdout"
 	}
 	return outputPath
@@ -33,3 +34,31 @@ func appendToFile(filePath, content string) error {
 
 	return nil
 }
+
+// writeToFile writes the given content to the file specified by the filePath.
+// If the file does not exist, it will be created.
+// If the file exists, it will be truncated before writing.
+// The function returns an error if the file cannot be opened or written to.
+func writeToFile(filePath, content string) error {
+	file, err := os.OpenFile(filePath, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(content)
+	if err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// writeOutput writes the content in the format to the file specified by the outputPath.
+// If the outputPath is empty, markdown is appended to $GITHUB_STEP_SUMMARY, and the other formats are written to stdout.
+func writeOutput(outputPath string, format Format, content string) error {
+	if outputPath != "" {
+		return writeToFile(outputPath, content)
+	}
+	return appendToFile(getOutputPath(format), content)
+}
@@ -8,24 +8,39 @@ import (
 
 func Test_getOutputPath(t *testing.T) {
 	tests := []struct {
-		name string
-		want string
+		name   string
+		env    string
+		format Format
+		want   string
 	}{
 		{
-			name: "with env",
-			want: "/path/to/out",
+			name:   "with env",
+			env:    "/path/to/out",
+			format: FormatMarkdown,
+			want:   "/path/to/out",
 		},
 		{
-			name: "without env",
-			want: "/dev/stdout",
+			name:   "without env",
+			format: FormatMarkdown,
+			want:   "/dev/stdout",
+		},
+		{
+			name:   "json with env",
+			env:    "/path/to/out",
+			format: FormatJSON,
+			want:   "/dev/stdout",
+		},
+		{
+			name:   "csv with env",
+			env:    "/path/to/out",
+			format: FormatCSV,
+			want:   "/dev/stdout",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.name == "with env" {
-				t.Setenv("GITHUB_STEP_SUMMARY", "/path/to/out")
-			}
-			if got := getOutputPath(); got != tt.want {
+			t.Setenv("GITHUB_STEP_SUMMARY", tt.env)
+			if got := getOutputPath(tt.format); got != tt.want {
 				t.Errorf("getOutputPath() = %v, want %v", got, tt.want)
 			}
 		})
@@ -104,3 +119,71 @@ func Test_appendToFile(t *testing.T) {
 		})
 	}
 }
+
+func Test_writeToFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	type args struct {
+		filePath string
+		content  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "Write to new file",
+			args: args{
+				filePath: filepath.Join(tempDir, "test1.txt"),
+				content:  "Hello",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Write to existing file",
+			args: args{
+				filePath: filepath.Join(tempDir, "test2.txt"),
+				content:  "World!",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Write to file in nonexistent directory",
+			args: args{
+				filePath: "/path/to/nonexistent/directory/file.txt",
+				content:  "Test",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.name == "Write to existing file" {
+				// For the test case that writes to an existing file, create the file beforehand
+				err := os.WriteFile(tt.args.filePath, []byte("Hello, "), 0o644)
+				if err != nil {
+					t.Fatalf("Failed to create file: %v", err)
+				}
+			}
+
+			err := writeToFile(tt.args.filePath, tt.args.content)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("writeToFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr {
+				// If no error is expected, verify the file content is replaced
+				content, err := os.ReadFile(tt.args.filePath)
+				if err != nil {
+					t.Errorf("Failed to read file: %v", err)
+					return
+				}
+				if string(content) != tt.args.content {
+					t.Errorf("File content mismatch. Got %q, want %q", string(content), tt.args.content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package bills
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// WorkflowStates is the list of the states of a workflow
+var WorkflowStates = []string{"active", "deleted", "disabled_fork", "disabled_inactivity", "disabled_manually"}
+
+// WorkflowFilter represents the conditions to include and exclude workflows from the report.
+// Names and paths are matched with glob patterns (e.g. release-*, .github/workflows/release-*.yml, dynamic/*/*).
+// A workflow is included if it matches one of the patterns of each kind of include conditions given,
+// and none of the exclude conditions.
+type WorkflowFilter struct {
+	IncludeNames  []string `yaml:"include_names"`  // Glob patterns of the workflow names to include
+	ExcludeNames  []string `yaml:"exclude_names"`  // Glob patterns of the workflow names to exclude
+	IncludePaths  []string `yaml:"include_paths"`  // Glob patterns of the workflow file paths to include
+	ExcludePaths  []string `yaml:"exclude_paths"`  // Glob patterns of the workflow file paths to exclude
+	IncludeStates []string `yaml:"include_states"` // Workflow states to include (e.g. active)
+	ExcludeStates []string `yaml:"exclude_states"` // Workflow states to exclude (e.g. disabled_manually)
+}
+
+// isEmpty returns true if the filter has no conditions
+func (f WorkflowFilter) isEmpty() bool {
+	return len(f.IncludeNames) == 0 && len(f.ExcludeNames) == 0 &&
+		len(f.IncludePaths) == 0 && len(f.ExcludePaths) == 0 &&
+		len(f.IncludeStates) == 0 && len(f.ExcludeStates) == 0
+}
+
+// validate returns an error if a pattern is malformed or a state is unknown
+func (f WorkflowFilter) validate() error {
+	for _, patterns := range [][]string{f.IncludeNames, f.ExcludeNames, f.IncludePaths, f.ExcludePaths} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid workflow filter pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	for _, states := range [][]string{f.IncludeStates, f.ExcludeStates} {
+		for _, state := range states {
+			if !slices.Contains(WorkflowStates, state) {
+				return fmt.Errorf("unsupported workflow state %q: must be one of %v", state, WorkflowStates)
+			}
+		}
+	}
+	return nil
+}
+
+// matches returns true if the workflow is included by the filter
+func (f WorkflowFilter) matches(workflow *github.Workflow) bool {
+	name, filePath, state := workflow.GetName(), workflow.GetPath(), workflow.GetState()
+	if len(f.IncludeNames) > 0 && !matchAny(f.IncludeNames, name) ||
+		len(f.IncludePaths) > 0 && !matchAny(f.IncludePaths, filePath) ||
+		len(f.IncludeStates) > 0 && !slices.Contains(f.IncludeStates, state) {
+		return false
+	}
+	return !matchAny(f.ExcludeNames, name) && !matchAny(f.ExcludePaths, filePath) && !slices.Contains(f.ExcludeStates, state)
+}
+
+// workflowSet is a set of workflow IDs. A nil set contains all workflows, as when no filter is given.
+type workflowSet map[int64]bool
+
+// contains returns true if the workflow of the ID is in the set
+func (s workflowSet) contains(id int64) bool {
+	return s == nil || s[id]
+}
+
+// matchAny returns true if the value matches any of the glob patterns
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filteredTitle returns the title of a total row, labelled as filtered if the workflows are filtered
+func filteredTitle(title string, filtered bool) string {
+	if filtered {
+		return title + " (filtered)"
+	}
+	return title
+}
+
+// filterNote formats the note on the workflows excluded by the filter, placed below a workflow table
+func filterNote(excluded int) string {
+	if excluded == 1 {
+		return "\n_1 workflow is excluded by the filters._\n"
+	}
+	return fmt.Sprintf("\n_%d workflows are excluded by the filters._\n", excluded)
+}
@@ -0,0 +1,153 @@
+package bills
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestWorkflowFilter_matches(t *testing.T) {
+	workflow := func(name, path, state string) *github.Workflow {
+		return &github.Workflow{Name: github.String(name), Path: github.String(path), State: github.String(state)}
+	}
+	release := workflow("Release", ".github/workflows/release-npm.yml", "active")
+	actbills := workflow("zzz_actbills", ".github/workflows/actbills.yml", "active")
+	dependabot := workflow("Dependabot Updates", "dynamic/dependabot/dependabot-updates", "active")
+	disabled := workflow("Nightly", ".github/workflows/nightly.yml", "disabled_manually")
+	workflows := []*github.Workflow{release, actbills, dependabot, disabled}
+
+	tests := []struct {
+		name   string
+		filter WorkflowFilter
+		want   []*github.Workflow
+	}{
+		{
+			name:   "empty",
+			filter: WorkflowFilter{},
+			want:   workflows,
+		},
+		{
+			name:   "exclude names and paths",
+			filter: WorkflowFilter{ExcludeNames: []string{"zzz_*"}, ExcludePaths: []string{"dynamic/*/*"}},
+			want:   []*github.Workflow{release, disabled},
+		},
+		{
+			name:   "include paths",
+			filter: WorkflowFilter{IncludePaths: []string{".github/workflows/release-*.yml", ".github/workflows/nightly.yml"}},
+			want:   []*github.Workflow{release, disabled},
+		},
+		{
+			name:   "include paths and states",
+			filter: WorkflowFilter{IncludePaths: []string{".github/workflows/*"}, IncludeStates: []string{"active"}},
+			want:   []*github.Workflow{release, actbills},
+		},
+		{
+			name:   "exclude states",
+			filter: WorkflowFilter{ExcludeStates: []string{"disabled_manually", "disabled_inactivity"}},
+			want:   []*github.Workflow{release, actbills, dependabot},
+		},
+		{
+			name:   "exclude wins over include",
+			filter: WorkflowFilter{IncludeNames: []string{"Release", "zzz_*"}, ExcludeNames: []string{"zzz_*"}},
+			want:   []*github.Workflow{release},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []*github.Workflow
+			for _, w := range workflows {
+				if tt.filter.matches(w) {
+					got = append(got, w)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("WorkflowFilter.matches() included %d workflows, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("WorkflowFilter.matches() included %s, want %s", got[i].GetName(), tt.want[i].GetName())
+				}
+			}
+		})
+	}
+}
+
+func TestWorkflowFilter_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  WorkflowFilter
+		wantErr bool
+	}{
+		{name: "valid", filter: WorkflowFilter{IncludePaths: []string{".github/workflows/release-*.yml"}, ExcludeStates: []string{"disabled_inactivity"}}},
+		{name: "malformed pattern", filter: WorkflowFilter{ExcludeNames: []string{"[zzz"}}, wantErr: true},
+		{name: "unknown state", filter: WorkflowFilter{IncludeStates: []string{"enabled"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("WorkflowFilter.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_generateRepositoryBillableTimes_filter(t *testing.T) {
+	workflows := map[string][]*github.Workflow{
+		"repo": {
+			{ID: github.Int64(1), Name: github.String("CI"), Path: github.String(".github/workflows/ci.yml")},
+			{ID: github.Int64(2), Name: github.String("zzz_actbills"), Path: github.String(".github/workflows/actbills.yml")},
+			{ID: github.Int64(3), Name: github.String("CodeQL"), Path: github.String("dynamic/github-code-scanning/codeql")},
+		},
+	}
+	client := github.NewClient(mock.NewMockedHTTPClient(mockRepositoryOptions(workflows, map[int64]bool{2: true, 3: true})...))
+	opts := Options{Filter: WorkflowFilter{ExcludeNames: []string{"zzz_*"}, ExcludePaths: []string{"dynamic/*/*"}}}
+
+	// the billable time of the excluded workflows is not fetched, so their failures are never reported
+	rbts, failures, err := generateRepositoryBillableTimes(context.Background(), client, "owner", []string{"repo"}, opts)
+	if err != nil {
+		t.Fatalf("generateRepositoryBillableTimes() error = %v", err)
+	}
+	if len(failures) != 0 {
+		t.Errorf("generateRepositoryBillableTimes() failures = %v, want none", failures)
+	}
+	if len(rbts[0].Workflows) != 1 || rbts[0].Workflows[0].ID != 1 {
+		t.Errorf("generateRepositoryBillableTimes() workflows = %v, want only CI", rbts[0].Workflows)
+	}
+	if rbts[0].ExcludedWorkflows != 2 {
+		t.Errorf("generateRepositoryBillableTimes() excluded = %d, want 2", rbts[0].ExcludedWorkflows)
+	}
+}
+
+func TestReport_render_filtered(t *testing.T) {
+	r := Report{
+		Organization: "org",
+		Pricing:      DefaultPricing,
+		Repositories: []RepositoryBillableTime{
+			{Repository: "org/repo", Workflows: WorkflowBillableTimes{{ID: 1, Name: "CI", BillableTime: BillableTime{Ubuntu: 10}}}, ExcludedWorkflows: 1},
+		},
+		Filtered:          true,
+		ExcludedWorkflows: 3,
+	}
+	wantRepository := `| CI | 10 | 0 | 0 | 10 | $0.08 |
+| **Total (filtered)** | **10** | **0** | **0** | **10** | **$0.08** |
+
+_1 workflow is excluded by the filters._
+`
+	wantOrganization := `| **Grand Total (filtered)** | **10** | **0** | **0** | **10** | **$0.08** |
+
+_3 workflows are excluded by the filters._
+`
+
+	got, err := r.render(FormatMarkdown, defaultTemplate)
+	if err != nil {
+		t.Fatalf("Report.render() error = %v", err)
+	}
+	for _, want := range []string{wantRepository, wantOrganization} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Report.render() = %v, want to contain %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package bills
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// forecastTrailingWindow is the window of the snapshots used to calculate the trailing rate of the forecast
+const forecastTrailingWindow = 7 * 24 * time.Hour
+
+// Plans maps the GitHub plans to the minutes included in them each month
+var Plans = map[string]int64{
+	"free":       2000,
+	"pro":        3000,
+	"team":       3000,
+	"enterprise": 50000,
+}
+
+// Forecast represents the billable time projected to the end of the billing cycle
+type Forecast struct {
+	CycleStart      time.Time     // Start of the billing cycle
+	CycleEnd        time.Time     // End of the billing cycle (exclusive)
+	Elapsed         time.Duration // Time elapsed since the start of the billing cycle
+	Current         BillableTime  // Billable time at the time the report was generated
+	Linear          BillableTime  // Projected from the average rate since the start of the billing cycle
+	Trailing        *BillableTime // Projected from the rate since the oldest snapshot in the trailing window (nil without snapshots)
+	Plan            string        // GitHub plan (empty if not specified)
+	IncludedMinutes int64         // Minutes included in the plan each month (0 if no plan is specified)
+}
+
+// billingCycleStart returns the start of the billing cycle containing now, which begins on the specified day of each month (UTC).
+// The day must be between 1 and 28, and a day less than 1 is treated as the 1st.
+func billingCycleStart(now time.Time, day int) time.Time {
+	if day < 1 {
+		day = 1
+	}
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), day, 0, 0, 0, 0, time.UTC)
+	if start.After(now) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start
+}
+
+// newForecast projects the billable time of the report to the end of the billing cycle starting on the specified day.
+// The trailing rate is calculated from the snapshots of the previous runs in the billing cycle, if any of them has the workflows of the report.
+func newForecast(r Report, snapshots snapshotStore, day int, plan string) Forecast {
+	start := billingCycleStart(r.GeneratedAt, day)
+	end := start.AddDate(0, 1, 0)
+	remaining := end.Sub(r.GeneratedAt)
+
+	f := Forecast{
+		CycleStart:      start,
+		CycleEnd:        end,
+		Elapsed:         r.GeneratedAt.Sub(start),
+		Current:         r.calculateTotal(),
+		Plan:            plan,
+		IncludedMinutes: Plans[plan],
+	}
+	f.Linear = extrapolate(BillableTime{}, f.Current, f.Elapsed, remaining)
+
+	since := r.GeneratedAt.Add(-forecastTrailingWindow)
+	if since.Before(start) {
+		since = start
+	}
+	if base := snapshots.oldest(since); base != nil && base.GeneratedAt.Before(r.GeneratedAt) {
+		// the rate is calculated only from the workflows in both the snapshot and the report,
+		// so the workflows and repositories added, deleted or filtered out since the snapshot do not distort it
+		if before, after, ok := base.matchedTotals(r); ok {
+			projected := extrapolate(before, after, r.GeneratedAt.Sub(base.GeneratedAt), remaining)
+			trailing := f.Current.add(projected.sub(after))
+			f.Trailing = &trailing
+		}
+	}
+
+	return f
+}
+
+// extrapolate projects the billable time by the rate from base to current over elapsed for the remaining time.
+// A negative rate (e.g. after a workflow is deleted) is treated as zero.
+func extrapolate(base, current BillableTime, elapsed, remaining time.Duration) BillableTime {
+	if elapsed <= 0 {
+		return current
+	}
+	project := func(b, c int64) int64 {
+		rate := math.Max(float64(c-b), 0) / float64(elapsed)
+		return c + int64(math.Round(rate*float64(remaining)))
+	}
+	return BillableTime{
+		Ubuntu:    project(base.Ubuntu, current.Ubuntu),
+		Windows:   project(base.Windows, current.Windows),
+		Macos:     project(base.Macos, current.Macos),
+		UbuntuMS:  project(base.UbuntuMS, current.UbuntuMS),
+		WindowsMS: project(base.WindowsMS, current.WindowsMS),
+		MacosMS:   project(base.MacosMS, current.MacosMS),
+	}
+}
+
+// generateMarkdown generates a markdown section with the billing cycle and a table of the current and the projected billable time.
+// The included minutes of the plan and the ratio of the weighted minutes to them are added if a plan is specified.
+func (f Forecast) generateMarkdown(pricing Pricing) string {
+	var sb strings.Builder
+	sb.WriteString("\n## Forecast\n\n")
+	sb.WriteString(fmt.Sprintf("Billing cycle: %s to %s (%.1f of %d days elapsed)\n\n",
+		f.CycleStart.Format(time.DateOnly), f.CycleEnd.AddDate(0, 0, -1).Format(time.DateOnly),
+		f.Elapsed.Hours()/24, int(f.CycleEnd.Sub(f.CycleStart).Hours()/24)))
+
+	columns := append([]string{"Model"}, billableTimeColumns...)
+	if f.IncludedMinutes > 0 {
+		columns = append(columns, fmt.Sprintf("Included in %s (min)", f.Plan), "Used (%)")
+	}
+	sb.WriteString(formatMarkdownHeader(columns))
+
+	row := func(title string, b BillableTime) string {
+		var extra []string
+		if f.IncludedMinutes > 0 {
+			used := pricing.weightedMinutes(b) / float64(f.IncludedMinutes) * 100
+			extra = []string{fmt.Sprint(f.IncludedMinutes), fmt.Sprintf("%.0f%%", used)}
+		}
+		return b.formatMarkdownRow(title, pricing, extra...)
+	}
+	sb.WriteString(row("Current", f.Current))
+	sb.WriteString(row("Linear", f.Linear))
+	if f.Trailing != nil {
+		sb.WriteString(row(fmt.Sprintf("Trailing %d days", int(forecastTrailingWindow.Hours()/24)), *f.Trailing))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,233 @@
+package bills
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_billingCycleStart(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		day  int
+		want time.Time
+	}{
+		{
+			name: "middle of month",
+			now:  time.Date(2024, 5, 17, 8, 30, 0, 0, time.UTC),
+			day:  1,
+			want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "other timezone",
+			now:  time.Date(2024, 6, 1, 5, 0, 0, 0, time.FixedZone("JST", 9*60*60)),
+			day:  1,
+			want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "after the start day",
+			now:  time.Date(2024, 5, 17, 8, 30, 0, 0, time.UTC),
+			day:  15,
+			want: time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "before the start day",
+			now:  time.Date(2024, 1, 10, 8, 30, 0, 0, time.UTC),
+			day:  15,
+			want: time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "unset",
+			now:  time.Date(2024, 5, 17, 8, 30, 0, 0, time.UTC),
+			day:  0,
+			want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := billingCycleStart(tt.now, tt.day); !got.Equal(tt.want) {
+				t.Errorf("billingCycleStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newForecast(t *testing.T) {
+	report := Report{
+		GeneratedAt: time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC),
+		Repositories: []RepositoryBillableTime{
+			{
+				Repository: "owner/repo",
+				Workflows: WorkflowBillableTimes{
+					{ID: 1, Name: "CI", BillableTime: BillableTime{Ubuntu: 100, Windows: 10}},
+				},
+			},
+		},
+	}
+	snapshot := func(day int, ubuntu, windows int64) Snapshot {
+		return Snapshot{
+			GeneratedAt: time.Date(2024, 5, day, 0, 0, 0, 0, time.UTC),
+			Repositories: []SnapshotRepository{
+				{Repository: "owner/repo", Workflows: []SnapshotWorkflow{{ID: 1, Name: "CI", Ubuntu: ubuntu, Windows: windows}}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		snapshots snapshotStore
+		want      Forecast
+	}{
+		{
+			name:      "linear",
+			snapshots: snapshotStore{},
+			want: Forecast{
+				CycleStart:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				CycleEnd:        time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+				Elapsed:         10 * 24 * time.Hour,
+				Current:         BillableTime{Ubuntu: 100, Windows: 10},
+				Linear:          BillableTime{Ubuntu: 310, Windows: 31},
+				Trailing:        nil,
+				Plan:            "team",
+				IncludedMinutes: 3000,
+			},
+		},
+		{
+			name: "trailing",
+			snapshots: snapshotStore{
+				Version: 1,
+				Snapshots: []Snapshot{
+					snapshot(2, 10, 0), // outside of the trailing window
+					snapshot(6, 80, 10),
+					snapshot(8, 90, 12), // workflow runs deleted since then
+				},
+			},
+			want: Forecast{
+				CycleStart:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				CycleEnd:        time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+				Elapsed:         10 * 24 * time.Hour,
+				Current:         BillableTime{Ubuntu: 100, Windows: 10},
+				Linear:          BillableTime{Ubuntu: 310, Windows: 31},
+				Trailing:        &BillableTime{Ubuntu: 184, Windows: 10},
+				Plan:            "team",
+				IncludedMinutes: 3000,
+			},
+		},
+		{
+			name: "trailing over the workflows of the report",
+			snapshots: snapshotStore{
+				Version: 1,
+				Snapshots: []Snapshot{
+					{
+						GeneratedAt: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+						Repositories: []SnapshotRepository{
+							{Repository: "owner/repo", Workflows: []SnapshotWorkflow{
+								{ID: 1, Name: "CI", Ubuntu: 80, Windows: 10},
+								{ID: 2, Name: "zzz_actbills", Ubuntu: 500}, // excluded by the filters since then
+							}},
+							{Repository: "owner/other", Workflows: []SnapshotWorkflow{{ID: 1, Name: "CI", Macos: 100}}},
+						},
+					},
+				},
+			},
+			want: Forecast{
+				CycleStart:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				CycleEnd:        time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+				Elapsed:         10 * 24 * time.Hour,
+				Current:         BillableTime{Ubuntu: 100, Windows: 10},
+				Linear:          BillableTime{Ubuntu: 310, Windows: 31},
+				Trailing:        &BillableTime{Ubuntu: 184, Windows: 10},
+				Plan:            "team",
+				IncludedMinutes: 3000,
+			},
+		},
+		{
+			name: "no workflow of the report in the snapshots",
+			snapshots: snapshotStore{
+				Version: 1,
+				Snapshots: []Snapshot{
+					{
+						GeneratedAt:  time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+						Repositories: []SnapshotRepository{{Repository: "owner/other", Workflows: []SnapshotWorkflow{{ID: 1, Name: "CI", Ubuntu: 10}}}},
+					},
+				},
+			},
+			want: Forecast{
+				CycleStart:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				CycleEnd:        time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+				Elapsed:         10 * 24 * time.Hour,
+				Current:         BillableTime{Ubuntu: 100, Windows: 10},
+				Linear:          BillableTime{Ubuntu: 310, Windows: 31},
+				Trailing:        nil,
+				Plan:            "team",
+				IncludedMinutes: 3000,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newForecast(report, tt.snapshots, 1, "team"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newForecast() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForecast_generateMarkdown(t *testing.T) {
+	forecast := Forecast{
+		CycleStart: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		CycleEnd:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		Elapsed:    10 * 24 * time.Hour,
+		Current:    BillableTime{Ubuntu: 100, Windows: 10},
+		Linear:     BillableTime{Ubuntu: 310, Windows: 31},
+		Trailing:   &BillableTime{Ubuntu: 184, Windows: 10},
+	}
+	withPlan := forecast
+	withPlan.Plan = "team"
+	withPlan.IncludedMinutes = 3000
+
+	tests := []struct {
+		name     string
+		forecast Forecast
+		want     string
+	}{
+		{
+			name:     "without plan",
+			forecast: forecast,
+			want: `
+## Forecast
+
+Billing cycle: 2024-05-01 to 2024-05-31 (10.0 of 31 days elapsed)
+
+| Model | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| Current | 100 | 10 | 0 | 120 | $0.96 |
+| Linear | 310 | 31 | 0 | 372 | $2.98 |
+| Trailing 7 days | 184 | 10 | 0 | 204 | $1.63 |
+`,
+		},
+		{
+			name:     "with plan",
+			forecast: withPlan,
+			want: `
+## Forecast
+
+Billing cycle: 2024-05-01 to 2024-05-31 (10.0 of 31 days elapsed)
+
+| Model | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) | Included in team (min) | Used (%) |
+| --- | --- | --- | --- | --- | --- | --- | --- |
+| Current | 100 | 10 | 0 | 120 | $0.96 | 3000 | 4% |
+| Linear | 310 | 31 | 0 | 372 | $2.98 | 3000 | 12% |
+| Trailing 7 days | 184 | 10 | 0 | 204 | $1.63 | 3000 | 7% |
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.forecast.generateMarkdown(DefaultPricing); got != tt.want {
+				t.Errorf("Forecast.generateMarkdown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,28 +3,87 @@ package bills
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v60/github"
 )
 
-// createGitHubClient creates a new GitHub API client with optional authentication token
-func createGitHubClient() *github.Client {
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return github.NewClient(nil)
+// createGitHubClient creates a new GitHub API client with optional authentication token.
+// If opts.AppID is set, the client is authenticated as the installation of the GitHub App on the owner instead.
+// The client is built with the enterprise URLs if opts.APIURL (default $GITHUB_API_URL) is set.
+// Requests failed by the rate limits or server errors are retried up to opts.MaxRetries times.
+func createGitHubClient(opts Options) (*github.Client, error) {
+	apiURL := opts.APIURL
+	if apiURL == "" {
+		apiURL = os.Getenv("GITHUB_API_URL")
 	}
-	return github.NewClient(nil).WithAuthToken(token)
+
+	transport := newRetryTransport(http.DefaultTransport, opts.MaxRetries, opts.MaxRateLimitWait)
+	if opts.AppID != 0 {
+		key, err := loadAppPrivateKey(opts.AppPrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		owner, err := reportOwner(opts)
+		if err != nil {
+			return nil, err
+		}
+		installation := newInstallationTransport(transport, opts.AppID, key, owner)
+		installation.apps, err = withEnterpriseURLs(installation.apps, apiURL, opts.UploadURL)
+		if err != nil {
+			return nil, err
+		}
+		return withEnterpriseURLs(github.NewClient(&http.Client{Transport: installation}), apiURL, opts.UploadURL)
+	}
+
+	client := github.NewClient(&http.Client{Transport: transport})
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		client = client.WithAuthToken(token)
+	}
+	return withEnterpriseURLs(client, apiURL, opts.UploadURL)
+}
+
+// withEnterpriseURLs returns a copy of the client sending the requests to the API URL and the upload URL.
+// The client is returned as is if the apiURL is empty.
+// If the uploadURL is empty, the upload URL is derived from the apiURL.
+func withEnterpriseURLs(client *github.Client, apiURL, uploadURL string) (*github.Client, error) {
+	if apiURL == "" {
+		return client, nil
+	}
+	if uploadURL != "" {
+		c, err := client.WithEnterpriseURLs(apiURL, uploadURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set API URL %s and upload URL %s: %w", apiURL, uploadURL, err)
+		}
+		return c, nil
+	}
+
+	c, err := client.WithEnterpriseURLs(apiURL, apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set API URL %s: %w", apiURL, err)
+	}
+	if host, ok := strings.CutPrefix(c.BaseURL.Host, "api."); ok {
+		// github.com and GHE.com serve uploads on the uploads subdomain
+		c.UploadURL = &url.URL{Scheme: c.BaseURL.Scheme, Host: "uploads." + host, Path: "/"}
+	} else {
+		// GitHub Enterprise Server serves uploads on /api/uploads of the same host
+		c.UploadURL = &url.URL{Scheme: c.BaseURL.Scheme, Host: c.BaseURL.Host, Path: "/api/uploads/"}
+	}
+	return c, nil
 }
 
 // fetchWorkflows retrieves a list of workflows for the specified repository
-func fetchWorkflows(client *github.Client, owner, repo string) ([]*github.Workflow, error) {
+func fetchWorkflows(ctx context.Context, client *github.Client, owner, repo string) ([]*github.Workflow, error) {
 	var allWorkflows []*github.Workflow
 	opts := &github.ListOptions{PerPage: 100}
 
 	for {
-		workflows, resp, err := client.Actions.ListWorkflows(context.Background(), owner, repo, opts)
+		workflows, resp, err := client.Actions.ListWorkflows(ctx, owner, repo, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -40,16 +99,202 @@ func fetchWorkflows(client *github.Client, owner, repo string) ([]*github.Workfl
 	return allWorkflows, nil
 }
 
-// fetchWorkflowBillableTime retrieves the billable time map for a specific workflow
-func fetchWorkflowBillMap(client *github.Client, owner, repo string, workflowID int64) (github.WorkflowBillMap, error) {
-	usage, _, err := client.Actions.GetWorkflowUsageByID(context.Background(), owner, repo, workflowID)
+// fetchPrivateRepositories retrieves a list of private repositories for the specified organization
+func fetchPrivateRepositories(ctx context.Context, client *github.Client, org string) ([]*github.Repository, error) {
+	var allRepositories []*github.Repository
+	opts := &github.RepositoryListByOrgOptions{
+		Type:        "private",
+		Sort:        "full_name",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		repositories, resp, err := client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allRepositories = append(allRepositories, repositories...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allRepositories, nil
+}
+
+// fetchWorkflowBillMap retrieves the billable time map for a specific workflow.
+// It returns an error if the usage has no billable time, so a malformed response is reported as a failure of the workflow.
+func fetchWorkflowBillMap(ctx context.Context, client *github.Client, owner, repo string, workflowID int64) (github.WorkflowBillMap, error) {
+	usage, _, err := client.Actions.GetWorkflowUsageByID(ctx, owner, repo, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if usage.Billable == nil {
+		return nil, fmt.Errorf("no billable time in the usage of workflow %d", workflowID)
+	}
+	return *usage.Billable, nil
+}
+
+// fetchWorkflowRuns retrieves a list of workflow runs created on or after the specified time for the repository
+func fetchWorkflowRuns(ctx context.Context, client *github.Client, owner, repo string, since time.Time) ([]*github.WorkflowRun, error) {
+	var allRuns []*github.WorkflowRun
+	opts := &github.ListWorkflowRunsOptions{
+		Created:     ">=" + since.Format(time.RFC3339),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		runs, resp, err := client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allRuns = append(allRuns, runs.WorkflowRuns...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allRuns, nil
+}
+
+// fetchWorkflowRunBillMap retrieves the billable time map of a specific workflow run, including the time of each job
+func fetchWorkflowRunBillMap(ctx context.Context, client *github.Client, owner, repo string, runID int64) (github.WorkflowRunBillMap, error) {
+	usage, _, err := client.Actions.GetWorkflowRunUsageByID(ctx, owner, repo, runID)
 	if err != nil {
 		return nil, err
 	}
 
+	if usage.Billable == nil {
+		return github.WorkflowRunBillMap{}, nil
+	}
 	return *usage.Billable, nil
 }
 
+// fetchWorkflowJobs retrieves a list of jobs of all attempts for a specific workflow run
+func fetchWorkflowJobs(ctx context.Context, client *github.Client, owner, repo string, runID int64) ([]*github.WorkflowJob, error) {
+	var allJobs []*github.WorkflowJob
+	opts := &github.ListWorkflowJobsOptions{
+		Filter:      "all",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		jobs, resp, err := client.Actions.ListWorkflowJobs(ctx, owner, repo, runID, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allJobs = append(allJobs, jobs.Jobs...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allJobs, nil
+}
+
+// fetchArtifacts retrieves a list of artifacts for the specified repository, including the expired ones
+func fetchArtifacts(ctx context.Context, client *github.Client, owner, repo string) ([]*github.Artifact, error) {
+	var allArtifacts []*github.Artifact
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		artifacts, resp, err := client.Actions.ListArtifacts(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allArtifacts = append(allArtifacts, artifacts.Artifacts...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allArtifacts, nil
+}
+
+// fetchWorkflowRun retrieves a specific workflow run
+func fetchWorkflowRun(ctx context.Context, client *github.Client, owner, repo string, runID int64) (*github.WorkflowRun, error) {
+	run, _, err := client.Actions.GetWorkflowRunByID(ctx, owner, repo, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// fetchCacheUsage retrieves the usage of the Actions cache for the specified repository
+func fetchCacheUsage(ctx context.Context, client *github.Client, owner, repo string) (*github.ActionsCacheUsage, error) {
+	usage, _, err := client.Actions.GetCacheUsageForRepo(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// fetchIssueComments retrieves a list of comments for the specified issue or pull request
+func fetchIssueComments(ctx context.Context, client *github.Client, owner, repo string, number int) ([]*github.IssueComment, error) {
+	var allComments []*github.IssueComment
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		comments, resp, err := client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allComments = append(allComments, comments...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allComments, nil
+}
+
+// fetchOpenIssues retrieves a list of open issues for the specified repository, excluding the pull requests
+func fetchOpenIssues(ctx context.Context, client *github.Client, owner, repo string) ([]*github.Issue, error) {
+	var allIssues []*github.Issue
+	opts := &github.IssueListByRepoOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		issues, resp, err := client.Issues.ListByRepo(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, issue := range issues {
+			if !issue.IsPullRequest() {
+				allIssues = append(allIssues, issue)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allIssues, nil
+}
+
 // extractOwnerAndRepo extracts the owner and repository name from the provided repository argument or environment variable
 func extractOwnerAndRepo(repo string) (string, string, error) {
 	var ownerRepo string
@@ -71,12 +316,54 @@ func extractOwnerAndRepo(repo string) (string, string, error) {
 	return parts[0], parts[1], nil
 }
 
+// validateRepositories returns an error naming the index of the first repository not in owner/repo format or listed twice
+func validateRepositories(repositories []string) error {
+	for i, repository := range repositories {
+		owner, repo, ok := strings.Cut(repository, "/")
+		if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return fmt.Errorf("[%d]: invalid repository name format: %s", i, repository)
+		}
+		if slices.Contains(repositories[:i], repository) {
+			return fmt.Errorf("[%d]: duplicate repository: %s", i, repository)
+		}
+	}
+	return nil
+}
+
+// reportOwner returns the owner of the repositories reported: opts.Organization, the owner of opts.Repositories, or the owner of opts.Repository.
+// The listed repositories must belong to a single owner, as a GitHub App installation and a billing summary are bound to one owner.
+func reportOwner(opts Options) (string, error) {
+	switch {
+	case opts.Organization != "":
+		return opts.Organization, nil
+	case len(opts.Repositories) > 0:
+		if err := validateRepositories(opts.Repositories); err != nil {
+			return "", fmt.Errorf("repositories%w", err)
+		}
+		owner, _, _ := strings.Cut(opts.Repositories[0], "/")
+		for _, repository := range opts.Repositories[1:] {
+			if other, _, _ := strings.Cut(repository, "/"); other != owner {
+				return "", fmt.Errorf("repositories of several owners are not supported with a GitHub App or the billing summary: %s and %s", owner, other)
+			}
+		}
+		return owner, nil
+	default:
+		owner, _, err := extractOwnerAndRepo(opts.Repository)
+		return owner, err
+	}
+}
+
 // getMinutesForEnv retrieves the total billable time in minutes for a specific environment
 func getMinutesForEnv(billMap github.WorkflowBillMap, env string) int64 {
+	return getMillisecondsForEnv(billMap, env) / 60000 // convert milliseconds to minutes
+}
+
+// getMillisecondsForEnv retrieves the total billable time in milliseconds for a specific environment
+func getMillisecondsForEnv(billMap github.WorkflowBillMap, env string) int64 {
 	bill, ok := billMap[env]
 	if !ok {
 		return 0
 	}
 
-	return bill.GetTotalMS() / 60000 // convert milliseconds to minutes
+	return bill.GetTotalMS()
 }
@@ -1,27 +1,139 @@
 package bills
 
 import (
+	"context"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v60/github"
 	"github.com/migueleliasweb/go-github-mock/src/mock"
 )
 
 func Test_createGitHubClient(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY", "")
+	t.Setenv("GITHUB_API_URL", "")
+	_, keyFile := newTestAppKey(t)
 	tests := []struct {
-		name string
-		want *github.Client
+		name      string
+		opts      Options
+		want      *retryTransport
+		wantOwner string // owner of the GitHub App installation (empty if not authenticated as a GitHub App)
+		wantErr   bool
 	}{
 		{
 			name: "basic",
-			want: github.NewClient(nil),
+			opts: Options{MaxRetries: 3, MaxRateLimitWait: time.Minute},
+			want: &retryTransport{base: http.DefaultTransport, maxRetries: 3, maxWait: time.Minute},
+		},
+		{
+			name:      "github app for repository",
+			opts:      Options{Repository: "owner/repo", AppID: 1, AppPrivateKeyFile: keyFile, MaxRetries: 3, MaxRateLimitWait: time.Minute},
+			want:      &retryTransport{base: http.DefaultTransport, maxRetries: 3, maxWait: time.Minute},
+			wantOwner: "owner",
+		},
+		{
+			name:      "github app for organization",
+			opts:      Options{Organization: "org", AppID: 1, AppPrivateKeyFile: keyFile},
+			want:      &retryTransport{base: http.DefaultTransport},
+			wantOwner: "org",
+		},
+		{
+			name:    "github app without private key",
+			opts:    Options{Organization: "org", AppID: 1},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := createGitHubClient(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("newGitHubClient() = %v, want %v", got, tt.want)
+			client, err := createGitHubClient(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("createGitHubClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			transport := client.Client().Transport
+			if tt.wantOwner != "" {
+				installation, ok := transport.(*installationTransport)
+				if !ok {
+					t.Fatalf("createGitHubClient() transport = %T, want *installationTransport", transport)
+				}
+				if installation.owner != tt.wantOwner {
+					t.Errorf("createGitHubClient() installation owner = %v, want %v", installation.owner, tt.wantOwner)
+				}
+				transport = installation.base
+			}
+			got, ok := transport.(*retryTransport)
+			if !ok {
+				t.Fatalf("createGitHubClient() transport = %T, want *retryTransport", transport)
+			}
+			if got.base != tt.want.base || got.maxRetries != tt.want.maxRetries || got.maxWait != tt.want.maxWait {
+				t.Errorf("createGitHubClient() transport = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_withEnterpriseURLs(t *testing.T) {
+	tests := []struct {
+		name          string
+		apiURL        string
+		uploadURL     string
+		wantBaseURL   string
+		wantUploadURL string
+		wantErr       bool
+	}{
+		{
+			name:          "default",
+			wantBaseURL:   "https://api.github.com/",
+			wantUploadURL: "https://uploads.github.com/",
+		},
+		{
+			name:          "enterprise server",
+			apiURL:        "https://github.example.com/api/v3",
+			wantBaseURL:   "https://github.example.com/api/v3/",
+			wantUploadURL: "https://github.example.com/api/uploads/",
+		},
+		{
+			name:          "enterprise server without path",
+			apiURL:        "https://github.example.com",
+			wantBaseURL:   "https://github.example.com/api/v3/",
+			wantUploadURL: "https://github.example.com/api/uploads/",
+		},
+		{
+			name:          "enterprise cloud with data residency",
+			apiURL:        "https://api.example.ghe.com",
+			wantBaseURL:   "https://api.example.ghe.com/",
+			wantUploadURL: "https://uploads.example.ghe.com/",
+		},
+		{
+			name:          "upload url",
+			apiURL:        "https://github.example.com/api/v3/",
+			uploadURL:     "https://uploads.example.com/api/uploads/",
+			wantBaseURL:   "https://github.example.com/api/v3/",
+			wantUploadURL: "https://uploads.example.com/api/uploads/",
+		},
+		{
+			name:    "invalid url",
+			apiURL:  "https://github.example.com/%zz",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := withEnterpriseURLs(github.NewClient(nil), tt.apiURL, tt.uploadURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("withEnterpriseURLs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.BaseURL.String() != tt.wantBaseURL || got.UploadURL.String() != tt.wantUploadURL {
+				t.Errorf("withEnterpriseURLs() = %v, %v, want %v, %v", got.BaseURL, got.UploadURL, tt.wantBaseURL, tt.wantUploadURL)
 			}
 		})
 	}
@@ -87,6 +199,32 @@ func Test_extractOwnerAndRepo(t *testing.T) {
 	}
 }
 
+func Test_reportOwner(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		want    string
+		wantErr bool
+	}{
+		{name: "organization", opts: Options{Organization: "org"}, want: "org", wantErr: false},
+		{name: "repository", opts: Options{Repository: "owner/repo"}, want: "owner", wantErr: false},
+		{name: "repositories", opts: Options{Repositories: []string{"owner/app", "owner/lib"}}, want: "owner", wantErr: false},
+		{name: "repositories of several owners", opts: Options{Repositories: []string{"owner/app", "other/lib"}}, want: "", wantErr: true},
+		{name: "invalid repositories", opts: Options{Repositories: []string{"owner/app", "lib"}}, want: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reportOwner(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reportOwner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("reportOwner() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_getMinutesForEnv(t *testing.T) {
 	u := int64(7200000)
 	w := int64(3000000)
@@ -204,7 +342,7 @@ func Test_fetchWorkflows(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := fetchWorkflows(tt.args.client, tt.args.owner, tt.args.repo)
+			got, err := fetchWorkflows(context.Background(), tt.args.client, tt.args.owner, tt.args.repo)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getWorkflows() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -216,6 +354,82 @@ func Test_fetchWorkflows(t *testing.T) {
 	}
 }
 
+func Test_fetchPrivateRepositories(t *testing.T) {
+	type args struct {
+		client *github.Client
+		org    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []*github.Repository
+		wantErr bool
+	}{
+		{
+			name: "pages",
+			args: args{
+				client: mockClientForListRepositories("pages"),
+				org:    "org",
+			},
+			want: []*github.Repository{
+				{Name: github.String("repo1")},
+				{Name: github.String("repo2")},
+				{Name: github.String("repo3")},
+			},
+			wantErr: false,
+		},
+		{
+			name: "ratelimit",
+			args: args{
+				client: mockClientForListRepositories("ratelimit"),
+				org:    "org",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetchPrivateRepositories(context.Background(), tt.args.client, tt.args.org)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fetchPrivateRepositories() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fetchPrivateRepositories() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// return mock GitHub Client for List Organization Repositories
+func mockClientForListRepositories(ptn string) *github.Client {
+	switch ptn {
+	case "ratelimit":
+		return github.NewClient(mock.NewMockedHTTPClient(
+			mock.WithRequestMatch(
+				mock.GetOrgsReposByOrg,
+				[]*github.Repository{},
+			),
+			mock.WithRateLimit(0, 0),
+		),
+		)
+	default:
+		return github.NewClient(mock.NewMockedHTTPClient(
+			mock.WithRequestMatchPages(
+				mock.GetOrgsReposByOrg,
+				[]*github.Repository{
+					{Name: github.String("repo1")},
+					{Name: github.String("repo2")},
+				},
+				[]*github.Repository{
+					{Name: github.String("repo3")},
+				},
+			),
+		))
+	}
+}
+
 // return mock GitHub Client for List Workflows
 func mockClientForListWorkflows(ptn string) *github.Client {
 	switch ptn {
@@ -303,20 +517,71 @@ func mockClientForWorkflowUsage(ptn string) *github.Client {
 	default:
 		u := int64(60000)
 		w := int64(600000)
+		// respond with the same usage to every request
 		return github.NewClient(mock.NewMockedHTTPClient(
-			mock.WithRequestMatch(
+			mock.WithRequestMatchHandler(
 				mock.GetReposActionsWorkflowsTimingByOwnerByRepoByWorkflowId,
-				github.WorkflowUsage{
-					Billable: &github.WorkflowBillMap{
-						"UBUNTU": &github.WorkflowBill{
-							TotalMS: &u,
-						},
-						"WINDOWS": &github.WorkflowBill{
-							TotalMS: &w,
+				http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+					_, _ = rw.Write(mock.MustMarshal(github.WorkflowUsage{
+						Billable: &github.WorkflowBillMap{
+							"UBUNTU": &github.WorkflowBill{
+								TotalMS: &u,
+							},
+							"WINDOWS": &github.WorkflowBill{
+								TotalMS: &w,
+							},
 						},
-					},
-				},
+					}))
+				}),
 			),
 		))
 	}
 }
+
+func Test_fetchWorkflowBillMap(t *testing.T) {
+	ms := int64(60000)
+	workflows := map[string][]*github.Workflow{
+		"repo": {
+			{ID: github.Int64(1), Name: github.String("CI")},
+			{ID: github.Int64(2), Name: github.String("Release")},
+		},
+	}
+	client := github.NewClient(mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposActionsWorkflowsByOwnerByRepo,
+			github.Workflows{Workflows: workflows["repo"]},
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposActionsWorkflowsTimingByOwnerByRepoByWorkflowId,
+			http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				// /repos/{owner}/{repo}/actions/workflows/{workflow_id}/timing
+				if strings.Split(r.URL.Path, "/")[6] == "2" {
+					_, _ = rw.Write([]byte("{}")) // timing without billable
+					return
+				}
+				_, _ = rw.Write(mock.MustMarshal(github.WorkflowUsage{
+					Billable: &github.WorkflowBillMap{"UBUNTU": &github.WorkflowBill{TotalMS: &ms}},
+				}))
+			}),
+		),
+	))
+
+	if _, err := fetchWorkflowBillMap(context.Background(), client, "owner", "repo", 1); err != nil {
+		t.Errorf("fetchWorkflowBillMap() error = %v", err)
+	}
+	if _, err := fetchWorkflowBillMap(context.Background(), client, "owner", "repo", 2); err == nil {
+		t.Error("fetchWorkflowBillMap() error = nil, want an error for the usage without billable time")
+	}
+
+	// the workflow without billable time is reported as a failure in tolerant mode
+	rbts, failures, err := generateRepositoryBillableTimes(context.Background(), client, "owner", []string{"repo"}, Options{Tolerant: true})
+	if err != nil {
+		t.Fatalf("generateRepositoryBillableTimes() error = %v", err)
+	}
+	if len(rbts[0].Workflows) != 1 || rbts[0].Workflows[0].ID != 1 {
+		t.Errorf("generateRepositoryBillableTimes() workflows = %v, want only CI", rbts[0].Workflows)
+	}
+	if len(failures) != 1 || failures[0].WorkflowID != 2 {
+		t.Errorf("generateRepositoryBillableTimes() failures = %v, want Release", failures)
+	}
+}
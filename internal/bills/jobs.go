@@ -0,0 +1,157 @@
+package bills
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+const jobsTableHeader = "| Workflow | Job | Runner OS | Runs | Minutes |\n| --- | --- | --- | --- | --- |\n"
+
+// JobBillableTime represents the total billable time of a job across the workflow runs in the billing cycle
+type JobBillableTime struct {
+	WorkflowID   int64  // ID of the workflow the job belongs to
+	WorkflowName string // Name of the workflow the job belongs to
+	Name         string // Job name (e.g. "test (macos-latest, 1.22)" for a matrix job)
+	OS           string // Runner OS (UBUNTU, WINDOWS or MACOS)
+	Runs         int    // Number of runs of the job
+	Minutes      int64  // Total billable time (in minutes). Each run is rounded up to the minute as GitHub does
+	TotalMS      int64  // Total billable time (in milliseconds)
+}
+
+// workflowRunJobs represents a workflow run and its jobs
+type workflowRunJobs struct {
+	run  *github.WorkflowRun
+	jobs []*github.WorkflowJob
+}
+
+// fetchWorkflowRunJobs retrieves the workflow runs of the included workflows created since the specified time and the jobs of each run.
+// The jobs of the runs are fetched by the worker pool.
+func fetchWorkflowRunJobs(ctx context.Context, client *github.Client, pool workerPool, owner, repo string, since time.Time, included workflowSet) ([]workflowRunJobs, error) {
+	all, err := fetchWorkflowRuns(ctx, client, owner, repo, since)
+	if err != nil {
+		return nil, err
+	}
+	var runs []*github.WorkflowRun
+	for _, run := range all {
+		if included.contains(run.GetWorkflowID()) {
+			runs = append(runs, run)
+		}
+	}
+
+	return runPool(ctx, pool, runs, func(ctx context.Context, run *github.WorkflowRun) (workflowRunJobs, error) {
+		jobs, err := fetchWorkflowJobs(ctx, client, owner, repo, run.GetID())
+		if err != nil {
+			return workflowRunJobs{}, err
+		}
+		return workflowRunJobs{run: run, jobs: jobs}, nil
+	})
+}
+
+// generateJobBillableTimes generates the billable time of each job in the specified workflow runs.
+// Runs of the same job are aggregated by workflow, job name and runner OS, and the top n jobs by billable time are returned.
+// The billable time of the runs is fetched by the worker pool.
+func generateJobBillableTimes(ctx context.Context, client *github.Client, pool workerPool, owner, repo string, runJobs []workflowRunJobs, n int) ([]JobBillableTime, error) {
+	billMaps, err := runPool(ctx, pool, runJobs, func(ctx context.Context, rj workflowRunJobs) (github.WorkflowRunBillMap, error) {
+		return fetchWorkflowRunBillMap(ctx, client, owner, repo, rj.run.GetID())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type jobKey struct {
+		workflowID int64
+		name       string
+		env        string
+	}
+	aggregated := make(map[jobKey]*JobBillableTime)
+
+	for i, rj := range runJobs {
+		run := rj.run
+		billMap := billMaps[i]
+
+		jobNames := make(map[int64]string, len(rj.jobs))
+		for _, job := range rj.jobs {
+			jobNames[job.GetID()] = job.GetName()
+		}
+
+		for env, bill := range billMap {
+			for _, jobRun := range bill.JobRuns {
+				jobID := int64(jobRun.GetJobID())
+				name, ok := jobNames[jobID]
+				if !ok {
+					name = fmt.Sprintf("job %d", jobID)
+				}
+
+				key := jobKey{workflowID: run.GetWorkflowID(), name: name, env: env}
+				jbt, ok := aggregated[key]
+				if !ok {
+					jbt = &JobBillableTime{WorkflowID: run.GetWorkflowID(), WorkflowName: run.GetName(), Name: name, OS: env}
+					aggregated[key] = jbt
+				}
+				jbt.Runs++
+				jbt.Minutes += (jobRun.GetDurationMS() + 59999) / 60000 // round up to the minute
+				jbt.TotalMS += jobRun.GetDurationMS()
+			}
+		}
+	}
+
+	jbts := make([]JobBillableTime, 0, len(aggregated))
+	for _, jbt := range aggregated {
+		jbts = append(jbts, *jbt)
+	}
+
+	return topJobs(jbts, n), nil
+}
+
+// topJobs returns the n jobs with the longest billable time.
+// Jobs with the same billable time are sorted by workflow name, job name and runner OS.
+func topJobs(jbts []JobBillableTime, n int) []JobBillableTime {
+	sort.Slice(jbts, func(i, j int) bool {
+		if jbts[i].Minutes != jbts[j].Minutes {
+			return jbts[i].Minutes > jbts[j].Minutes
+		}
+		if jbts[i].TotalMS != jbts[j].TotalMS {
+			return jbts[i].TotalMS > jbts[j].TotalMS
+		}
+		if jbts[i].WorkflowName != jbts[j].WorkflowName {
+			return jbts[i].WorkflowName < jbts[j].WorkflowName
+		}
+		if jbts[i].Name != jbts[j].Name {
+			return jbts[i].Name < jbts[j].Name
+		}
+		return jbts[i].OS < jbts[j].OS
+	})
+	if len(jbts) > n {
+		jbts = jbts[:n]
+	}
+	return jbts
+}
+
+// generateJobsMarkdown generates a markdown section with a table of the top jobs by billable time
+func generateJobsMarkdown(heading string, jbts []JobBillableTime) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n%s Top jobs by billable time\n\n", heading))
+	sb.WriteString(jobsTableHeader)
+	for _, jbt := range jbts {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %d | %d |\n", escapeMarkdownCell(jbt.WorkflowName), escapeMarkdownCell(jbt.Name), formatRunnerOS(jbt.OS), jbt.Runs, jbt.Minutes))
+	}
+	return sb.String()
+}
+
+// formatRunnerOS formats the runner OS in the same way as the table headers (e.g. UBUNTU -> Ubuntu)
+func formatRunnerOS(env string) string {
+	if env == "" {
+		return env
+	}
+	return strings.ToUpper(env[:1]) + strings.ToLower(env[1:])
+}
+
+// escapeMarkdownCell escapes the pipes and line breaks of a markdown table cell
+func escapeMarkdownCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,220 @@
+package bills
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func Test_fetchWorkflowRunJobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *github.Client
+		included workflowSet
+		wantRuns int
+		wantJobs int
+		wantErr  bool
+	}{
+		{
+			name:     "basic",
+			client:   mockClientForJobs("basic"),
+			wantRuns: 3,
+			wantJobs: 4,
+			wantErr:  false,
+		},
+		{
+			name:     "excluded workflow",
+			client:   mockClientForJobs("basic"),
+			included: workflowSet{1: true},
+			wantRuns: 2,
+			wantJobs: 4,
+			wantErr:  false,
+		},
+		{
+			name:     "ratelimit",
+			client:   mockClientForJobs("ratelimit"),
+			wantRuns: 0,
+			wantJobs: 0,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetchWorkflowRunJobs(context.Background(), tt.client, newWorkerPool(1, false), "owner", "repo", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), tt.included)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fetchWorkflowRunJobs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			jobs := 0
+			for _, rj := range got {
+				jobs += len(rj.jobs)
+			}
+			if len(got) != tt.wantRuns || jobs != tt.wantJobs {
+				t.Errorf("fetchWorkflowRunJobs() = %d runs and %d jobs, want %d runs and %d jobs", len(got), jobs, tt.wantRuns, tt.wantJobs)
+			}
+		})
+	}
+}
+
+func Test_generateJobBillableTimes(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		included workflowSet
+		want     []JobBillableTime
+		wantErr  bool
+	}{
+		{
+			name: "basic",
+			n:    10,
+			want: []JobBillableTime{
+				{WorkflowID: 1, WorkflowName: "CI", Name: "test (macos-latest)", OS: "MACOS", Runs: 2, Minutes: 12, TotalMS: 720000},
+				{WorkflowID: 1, WorkflowName: "CI", Name: "test (ubuntu-latest)", OS: "UBUNTU", Runs: 2, Minutes: 3, TotalMS: 150000},
+				{WorkflowID: 2, WorkflowName: "Release", Name: "job 99", OS: "UBUNTU", Runs: 1, Minutes: 1, TotalMS: 1000},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "excluded workflow",
+			n:        10,
+			included: workflowSet{1: true},
+			want: []JobBillableTime{
+				{WorkflowID: 1, WorkflowName: "CI", Name: "test (macos-latest)", OS: "MACOS", Runs: 2, Minutes: 12, TotalMS: 720000},
+				{WorkflowID: 1, WorkflowName: "CI", Name: "test (ubuntu-latest)", OS: "UBUNTU", Runs: 2, Minutes: 3, TotalMS: 150000},
+			},
+			wantErr: false,
+		},
+		{
+			name: "top",
+			n:    1,
+			want: []JobBillableTime{
+				{WorkflowID: 1, WorkflowName: "CI", Name: "test (macos-latest)", OS: "MACOS", Runs: 2, Minutes: 12, TotalMS: 720000},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := mockClientForJobs("basic")
+			runJobs, err := fetchWorkflowRunJobs(context.Background(), client, newWorkerPool(1, false), "owner", "repo", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), tt.included)
+			if err != nil {
+				t.Fatalf("fetchWorkflowRunJobs() error = %v", err)
+			}
+			got, err := generateJobBillableTimes(context.Background(), client, newWorkerPool(1, false), "owner", "repo", runJobs, tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateJobBillableTimes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateJobBillableTimes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generateJobsMarkdown(t *testing.T) {
+	jbts := []JobBillableTime{
+		{WorkflowID: 1, WorkflowName: "CI", Name: "test (macos-latest)", OS: "MACOS", Runs: 2, Minutes: 12, TotalMS: 720000},
+		{WorkflowID: 1, WorkflowName: "CI", Name: "test (ubuntu-latest)", OS: "UBUNTU", Runs: 2, Minutes: 3, TotalMS: 150000},
+		{WorkflowID: 2, WorkflowName: "Lint | Format", Name: "check (a|b)\nstyle", OS: "UBUNTU", Runs: 1, Minutes: 1, TotalMS: 60000},
+	}
+	want := `
+## Top jobs by billable time
+
+| Workflow | Job | Runner OS | Runs | Minutes |
+| --- | --- | --- | --- | --- |
+| CI | test (macos-latest) | Macos | 2 | 12 |
+| CI | test (ubuntu-latest) | Ubuntu | 2 | 3 |
+| Lint \| Format | check (a\|b) style | Ubuntu | 1 | 1 |
+`
+	if got := generateJobsMarkdown("##", jbts); got != want {
+		t.Errorf("generateJobsMarkdown() = %v, want %v", got, want)
+	}
+}
+
+// return mock GitHub Client for workflow runs, their timing and their jobs
+func mockClientForJobs(ptn string) *github.Client {
+	switch ptn {
+	case "ratelimit":
+		return github.NewClient(mock.NewMockedHTTPClient(
+			mock.WithRequestMatch(
+				mock.GetReposActionsRunsByOwnerByRepo,
+				github.WorkflowRuns{},
+			),
+			mock.WithRateLimit(0, 0),
+		))
+	default:
+		return github.NewClient(mock.NewMockedHTTPClient(
+			mock.WithRequestMatch(
+				mock.GetReposActionsRunsByOwnerByRepo,
+				github.WorkflowRuns{
+					WorkflowRuns: []*github.WorkflowRun{
+						{ID: github.Int64(11), WorkflowID: github.Int64(1), Name: github.String("CI")},
+						{ID: github.Int64(12), WorkflowID: github.Int64(1), Name: github.String("CI")},
+						{ID: github.Int64(13), WorkflowID: github.Int64(2), Name: github.String("Release")},
+					},
+				},
+			),
+			mock.WithRequestMatch(
+				mock.GetReposActionsRunsTimingByOwnerByRepoByRunId,
+				github.WorkflowRunUsage{
+					Billable: &github.WorkflowRunBillMap{
+						"UBUNTU": &github.WorkflowRunBill{
+							JobRuns: []*github.WorkflowRunJobRun{
+								{JobID: github.Int(101), DurationMS: github.Int64(90000)},
+							},
+						},
+						"MACOS": &github.WorkflowRunBill{
+							JobRuns: []*github.WorkflowRunJobRun{
+								{JobID: github.Int(102), DurationMS: github.Int64(420000)},
+							},
+						},
+					},
+				},
+				github.WorkflowRunUsage{
+					Billable: &github.WorkflowRunBillMap{
+						"UBUNTU": &github.WorkflowRunBill{
+							JobRuns: []*github.WorkflowRunJobRun{
+								{JobID: github.Int(201), DurationMS: github.Int64(60000)},
+							},
+						},
+						"MACOS": &github.WorkflowRunBill{
+							JobRuns: []*github.WorkflowRunJobRun{
+								{JobID: github.Int(202), DurationMS: github.Int64(300000)},
+							},
+						},
+					},
+				},
+				github.WorkflowRunUsage{
+					Billable: &github.WorkflowRunBillMap{
+						"UBUNTU": &github.WorkflowRunBill{
+							JobRuns: []*github.WorkflowRunJobRun{
+								{JobID: github.Int(99), DurationMS: github.Int64(1000)},
+							},
+						},
+					},
+				},
+			),
+			mock.WithRequestMatch(
+				mock.GetReposActionsRunsJobsByOwnerByRepoByRunId,
+				github.Jobs{
+					Jobs: []*github.WorkflowJob{
+						{ID: github.Int64(101), Name: github.String("test (ubuntu-latest)")},
+						{ID: github.Int64(102), Name: github.String("test (macos-latest)")},
+					},
+				},
+				github.Jobs{
+					Jobs: []*github.WorkflowJob{
+						{ID: github.Int64(201), Name: github.String("test (ubuntu-latest)")},
+						{ID: github.Int64(202), Name: github.String("test (macos-latest)")},
+					},
+				},
+				github.Jobs{},
+			),
+		))
+	}
+}
@@ -0,0 +1,261 @@
+package bills
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// jsonReportVersion is the version of the JSON report schema.
+// It is incremented only when a backward incompatible change is made to the schema.
+const jsonReportVersion = 1
+
+// jsonReport represents the JSON report document
+type jsonReport struct {
+	Version      int                 `json:"version"`
+	GeneratedAt  time.Time           `json:"generated_at"`
+	Organization string              `json:"organization,omitempty"`
+	Repositories []jsonRepository    `json:"repositories"`
+	Total        jsonBillableTime    `json:"total"`
+	Forecast     *jsonForecast       `json:"forecast,omitempty"`
+	Failures     []jsonFailure       `json:"failures,omitempty"`
+	Billing      *jsonBillingSummary `json:"billing,omitempty"`
+
+	Filtered          bool `json:"filtered,omitempty"`           // true if the workflows are filtered
+	ExcludedWorkflows int  `json:"excluded_workflows,omitempty"` // number of the workflows excluded by the filters
+}
+
+// jsonRepository represents the billable times for the workflows in a repository
+type jsonRepository struct {
+	Repository string           `json:"repository"`
+	Workflows  []jsonWorkflow   `json:"workflows"`
+	Total      jsonBillableTime `json:"total"`
+	Jobs       []jsonJob        `json:"jobs,omitempty"`
+
+	LargerRunners []jsonLargerRunner `json:"larger_runners,omitempty"`
+	Storage       *jsonStorage       `json:"storage,omitempty"`
+
+	ExcludedWorkflows int `json:"excluded_workflows,omitempty"` // number of the workflows excluded by the filters
+}
+
+// jsonWorkflow represents a workflow and its billable time
+type jsonWorkflow struct {
+	ID       int64            `json:"id"`
+	Name     string           `json:"name"`
+	Path     string           `json:"path"`
+	State    string           `json:"state"`
+	Billable jsonBillableTime `json:"billable"`
+}
+
+// jsonJob represents the total billable time of a job across the workflow runs in the billing cycle
+type jsonJob struct {
+	WorkflowID   int64  `json:"workflow_id"`
+	WorkflowName string `json:"workflow_name"`
+	Name         string `json:"name"`
+	OS           string `json:"os"`
+	Runs         int    `json:"runs"`
+	TotalMS      int64  `json:"total_ms"`
+	Minutes      int64  `json:"minutes"`
+}
+
+// jsonLargerRunner represents the total billable time of the jobs of a workflow run on a larger runner SKU
+type jsonLargerRunner struct {
+	WorkflowID   int64    `json:"workflow_id"`
+	WorkflowName string   `json:"workflow_name"`
+	SKU          string   `json:"sku"`
+	Jobs         int      `json:"jobs"`
+	TotalMS      int64    `json:"total_ms"`
+	Minutes      int64    `json:"minutes"`
+	CostUSD      *float64 `json:"cost_usd"` // null if the price of the SKU is unknown
+}
+
+// jsonStorage represents the storage used by the artifacts and the caches of a repository
+type jsonStorage struct {
+	Artifacts  []jsonArtifact        `json:"artifacts"`
+	Workflows  []jsonWorkflowStorage `json:"workflows"`
+	CostUSD    float64               `json:"cost_usd"` // estimated cost of the artifacts per month
+	CacheBytes int64                 `json:"cache_bytes"`
+	Caches     int                   `json:"caches"`
+}
+
+// jsonArtifact represents an artifact and the workflow run producing it
+type jsonArtifact struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	SizeBytes    int64     `json:"size_bytes"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RunID        int64     `json:"run_id"`
+	WorkflowID   int64     `json:"workflow_id,omitempty"`
+	WorkflowName string    `json:"workflow_name,omitempty"`
+}
+
+// jsonWorkflowStorage represents the total size of the artifacts produced by a workflow
+type jsonWorkflowStorage struct {
+	WorkflowID   int64   `json:"workflow_id"`
+	WorkflowName string  `json:"workflow_name"`
+	Artifacts    int     `json:"artifacts"`
+	SizeBytes    int64   `json:"size_bytes"`
+	CostUSD      float64 `json:"cost_usd"` // estimated cost per month
+}
+
+// jsonForecast represents the billable time projected to the end of the billing cycle
+type jsonForecast struct {
+	CycleStart      time.Time         `json:"cycle_start"`
+	CycleEnd        time.Time         `json:"cycle_end"`
+	Plan            string            `json:"plan,omitempty"`
+	IncludedMinutes int64             `json:"included_minutes,omitempty"`
+	Linear          jsonBillableTime  `json:"linear"`
+	Trailing        *jsonBillableTime `json:"trailing"` // null without snapshots of the previous runs
+}
+
+// jsonFailure represents a workflow or a repository that could not be fetched
+type jsonFailure struct {
+	Repository string `json:"repository"`
+	WorkflowID int64  `json:"workflow_id,omitempty"`
+	Workflow   string `json:"workflow,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// jsonBillableTime represents the billable time for each environment with the weighted minutes and the cost
+type jsonBillableTime struct {
+	Ubuntu          jsonEnvironment `json:"ubuntu"`
+	Windows         jsonEnvironment `json:"windows"`
+	Macos           jsonEnvironment `json:"macos"`
+	WeightedMinutes float64         `json:"weighted_minutes"`
+	CostUSD         float64         `json:"cost_usd"`
+}
+
+// jsonEnvironment represents the billable time for an environment
+type jsonEnvironment struct {
+	TotalMS int64 `json:"total_ms"`
+	Minutes int64 `json:"minutes"`
+}
+
+// generateJSONReport generates a JSON-formatted report based on the provided Report data
+func (r Report) generateJSONReport() (string, error) {
+	doc := jsonReport{
+		Version:      jsonReportVersion,
+		GeneratedAt:  r.GeneratedAt,
+		Organization: r.Organization,
+		Repositories: []jsonRepository{},
+		Total:        newJSONBillableTime(r.calculateTotal(), r.Pricing),
+
+		Filtered:          r.Filtered,
+		ExcludedWorkflows: r.ExcludedWorkflows,
+	}
+
+	for _, rbt := range r.Repositories {
+		repository := jsonRepository{
+			Repository: rbt.Repository,
+			Workflows:  []jsonWorkflow{},
+			Total:      newJSONBillableTime(rbt.Workflows.calculateTotal(), r.Pricing),
+
+			ExcludedWorkflows: rbt.ExcludedWorkflows,
+		}
+		for _, wbt := range rbt.Workflows.sortWorkflows() {
+			repository.Workflows = append(repository.Workflows, jsonWorkflow{
+				ID:       wbt.ID,
+				Name:     wbt.Name,
+				Path:     wbt.Path,
+				State:    wbt.State,
+				Billable: newJSONBillableTime(wbt.BillableTime, r.Pricing),
+			})
+		}
+		for _, jbt := range rbt.Jobs {
+			repository.Jobs = append(repository.Jobs, jsonJob{
+				WorkflowID:   jbt.WorkflowID,
+				WorkflowName: jbt.WorkflowName,
+				Name:         jbt.Name,
+				OS:           jbt.OS,
+				Runs:         jbt.Runs,
+				TotalMS:      jbt.TotalMS,
+				Minutes:      jbt.Minutes,
+			})
+		}
+		for _, lrbt := range rbt.LargerRunners {
+			larger := jsonLargerRunner{
+				WorkflowID:   lrbt.WorkflowID,
+				WorkflowName: lrbt.WorkflowName,
+				SKU:          lrbt.SKU,
+				Jobs:         lrbt.Jobs,
+				TotalMS:      lrbt.TotalMS,
+				Minutes:      lrbt.Minutes,
+			}
+			if price, ok := r.Pricing.LargerRunners[lrbt.SKU]; ok {
+				cost := math.Round(float64(lrbt.Minutes)*price*100) / 100 // round to cents
+				larger.CostUSD = &cost
+			}
+			repository.LargerRunners = append(repository.LargerRunners, larger)
+		}
+		if rbt.Storage != nil {
+			repository.Storage = newJSONStorage(*rbt.Storage, r.Pricing)
+		}
+		doc.Repositories = append(doc.Repositories, repository)
+	}
+
+	if f := r.Forecast; f != nil {
+		doc.Forecast = &jsonForecast{
+			CycleStart:      f.CycleStart,
+			CycleEnd:        f.CycleEnd,
+			Plan:            f.Plan,
+			IncludedMinutes: f.IncludedMinutes,
+			Linear:          newJSONBillableTime(f.Linear, r.Pricing),
+		}
+		if f.Trailing != nil {
+			trailing := newJSONBillableTime(*f.Trailing, r.Pricing)
+			doc.Forecast.Trailing = &trailing
+		}
+	}
+
+	for _, f := range r.Failures {
+		doc.Failures = append(doc.Failures, jsonFailure(f))
+	}
+
+	if r.Billing != nil {
+		billing := newJSONBillingSummary(*r.Billing)
+		doc.Billing = &billing
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(data) + "\n", nil
+}
+
+// newJSONStorage converts a RepositoryStorage to a jsonStorage
+func newJSONStorage(s RepositoryStorage, pricing Pricing) *jsonStorage {
+	storage := &jsonStorage{
+		Artifacts:  []jsonArtifact{},
+		Workflows:  []jsonWorkflowStorage{},
+		CostUSD:    math.Round(pricing.storageCost(s.artifactBytes())*100) / 100, // round to cents
+		CacheBytes: s.CacheBytes,
+		Caches:     s.Caches,
+	}
+	for _, as := range s.Artifacts {
+		storage.Artifacts = append(storage.Artifacts, jsonArtifact(as))
+	}
+	for _, ws := range s.Workflows {
+		storage.Workflows = append(storage.Workflows, jsonWorkflowStorage{
+			WorkflowID:   ws.WorkflowID,
+			WorkflowName: ws.WorkflowName,
+			Artifacts:    ws.Artifacts,
+			SizeBytes:    ws.SizeBytes,
+			CostUSD:      math.Round(pricing.storageCost(ws.SizeBytes)*100) / 100,
+		})
+	}
+	return storage
+}
+
+// newJSONBillableTime converts a BillableTime to a jsonBillableTime
+func newJSONBillableTime(b BillableTime, pricing Pricing) jsonBillableTime {
+	return jsonBillableTime{
+		Ubuntu:          jsonEnvironment{TotalMS: b.UbuntuMS, Minutes: b.Ubuntu},
+		Windows:         jsonEnvironment{TotalMS: b.WindowsMS, Minutes: b.Windows},
+		Macos:           jsonEnvironment{TotalMS: b.MacosMS, Minutes: b.Macos},
+		WeightedMinutes: pricing.weightedMinutes(b),
+		CostUSD:         math.Round(pricing.cost(b)*100) / 100, // round to cents
+	}
+}
@@ -0,0 +1,186 @@
+package bills
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReport_generateJSONReport(t *testing.T) {
+	report := Report{
+		Pricing:     DefaultPricing,
+		GeneratedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Repositories: []RepositoryBillableTime{
+			{
+				Repository: "owner/repo",
+				Workflows: WorkflowBillableTimes{
+					{
+						ID:    2,
+						Name:  "Workflow2",
+						Path:  ".github/workflows/workflow2.yml",
+						State: "active",
+						BillableTime: BillableTime{
+							Windows:   1,
+							WindowsMS: 90000,
+						},
+					},
+					{
+						ID:    1,
+						Name:  "Workflow1",
+						Path:  ".github/workflows/workflow1.yml",
+						State: "active",
+						BillableTime: BillableTime{
+							Ubuntu:   2,
+							Macos:    1,
+							UbuntuMS: 150000,
+							MacosMS:  60000,
+						},
+					},
+				},
+			},
+		},
+	}
+	want := `{
+  "version": 1,
+  "generated_at": "2024-05-01T12:00:00Z",
+  "repositories": [
+    {
+      "repository": "owner/repo",
+      "workflows": [
+        {
+          "id": 1,
+          "name": "Workflow1",
+          "path": ".github/workflows/workflow1.yml",
+          "state": "active",
+          "billable": {
+            "ubuntu": {
+              "total_ms": 150000,
+              "minutes": 2
+            },
+            "windows": {
+              "total_ms": 0,
+              "minutes": 0
+            },
+            "macos": {
+              "total_ms": 60000,
+              "minutes": 1
+            },
+            "weighted_minutes": 12,
+            "cost_usd": 0.1
+          }
+        },
+        {
+          "id": 2,
+          "name": "Workflow2",
+          "path": ".github/workflows/workflow2.yml",
+          "state": "active",
+          "billable": {
+            "ubuntu": {
+              "total_ms": 0,
+              "minutes": 0
+            },
+            "windows": {
+              "total_ms": 90000,
+              "minutes": 1
+            },
+            "macos": {
+              "total_ms": 0,
+              "minutes": 0
+            },
+            "weighted_minutes": 2,
+            "cost_usd": 0.02
+          }
+        }
+      ],
+      "total": {
+        "ubuntu": {
+          "total_ms": 150000,
+          "minutes": 2
+        },
+        "windows": {
+          "total_ms": 90000,
+          "minutes": 1
+        },
+        "macos": {
+          "total_ms": 60000,
+          "minutes": 1
+        },
+        "weighted_minutes": 14,
+        "cost_usd": 0.11
+      }
+    }
+  ],
+  "total": {
+    "ubuntu": {
+      "total_ms": 150000,
+      "minutes": 2
+    },
+    "windows": {
+      "total_ms": 90000,
+      "minutes": 1
+    },
+    "macos": {
+      "total_ms": 60000,
+      "minutes": 1
+    },
+    "weighted_minutes": 14,
+    "cost_usd": 0.11
+  }
+}
+`
+	tests := []struct {
+		name    string
+		r       Report
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "basic",
+			r:       report,
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name: "empty",
+			r: Report{
+				Organization: "org",
+				GeneratedAt:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			},
+			want: `{
+  "version": 1,
+  "generated_at": "2024-05-01T12:00:00Z",
+  "organization": "org",
+  "repositories": [],
+  "total": {
+    "ubuntu": {
+      "total_ms": 0,
+      "minutes": 0
+    },
+    "windows": {
+      "total_ms": 0,
+      "minutes": 0
+    },
+    "macos": {
+      "total_ms": 0,
+      "minutes": 0
+    },
+    "weighted_minutes": 0,
+    "cost_usd": 0
+  }
+}
+`,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.r.generateJSONReport()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Report.generateJSONReport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Report.generateJSONReport() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package bills
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// notifyTopWorkflows is the number of the workflows with the highest cost listed in the Slack message
+	notifyTopWorkflows = 10
+	// slackNameWidth is the maximum width of the workflow names in the table of the Slack message
+	slackNameWidth = 40
+)
+
+// notifier posts the report to the webhooks
+type notifier struct {
+	client *http.Client
+	dryRun bool      // Print the payloads instead of sending them
+	out    io.Writer // Writer the payloads are printed to in dry-run mode
+}
+
+// newNotifier creates a notifier retrying the requests failed by the rate limits and server errors as configured by the options
+func newNotifier(opts Options, out io.Writer) notifier {
+	return notifier{
+		client: &http.Client{Transport: newRetryTransport(http.DefaultTransport, opts.MaxRetries, opts.MaxRateLimitWait)},
+		dryRun: opts.NotifyDryRun,
+		out:    out,
+	}
+}
+
+// notify posts the summary of the report of the subject to opts.SlackWebhookURL and the JSON report to opts.WebhookURL, if set
+func (n notifier) notify(ctx context.Context, opts Options, r Report, subject string) error {
+	if opts.SlackWebhookURL != "" {
+		payload, err := json.Marshal(newSlackMessage(r, subject))
+		if err != nil {
+			return fmt.Errorf("failed to encode Slack message: %w", err)
+		}
+		if err := n.post(ctx, "Slack webhook", opts.SlackWebhookURL, payload); err != nil {
+			return err
+		}
+	}
+	if opts.WebhookURL != "" {
+		payload, err := r.generateJSONReport()
+		if err != nil {
+			return err
+		}
+		if err := n.post(ctx, "webhook", opts.WebhookURL, []byte(payload)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post sends the JSON payload to the webhook URL, or prints it in dry-run mode.
+// The URL is not included in the errors and the output as it usually contains a secret.
+func (n notifier) post(ctx context.Context, name, webhookURL string, payload []byte) error {
+	if n.dryRun {
+		_, err := fmt.Fprintf(n.out, "Dry run: the following payload is not sent to the %s\n%s\n", name, payload)
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request to the %s: %w", name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to post to the %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to post to the %s: %s: %s", name, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// slackMessage represents a message posted to a Slack incoming webhook
+type slackMessage struct {
+	Text   string       `json:"text"` // Fallback text shown in the notifications
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackBlock represents a Block Kit layout block
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText represents a Block Kit text object
+type slackText struct {
+	Type string `json:"type"` // plain_text or mrkdwn
+	Text string `json:"text"`
+}
+
+// newSlackMessage creates a Slack message with the total billable time of the report and the workflows with the highest cost
+func newSlackMessage(r Report, subject string) slackMessage {
+	data := newTemplateData(r)
+	total := data.Total
+	title := fmt.Sprintf("Actions billable time for %s", subject)
+
+	changes := make([]string, 3) // change of each environment since the previous snapshot, e.g. " (+10)"
+	if total.Change != nil {
+		for i, delta := range formatDeltas(*total.Change, BillableTime{}) {
+			changes[i] = fmt.Sprintf(" (%s)", delta)
+		}
+	}
+	fields := []slackText{
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Ubuntu*\n%d min%s", total.Ubuntu, changes[0])},
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Windows*\n%d min%s", total.Windows, changes[1])},
+		{Type: "mrkdwn", Text: fmt.Sprintf("*macOS*\n%d min%s", total.Macos, changes[2])},
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Weighted*\n%s min", formatMinutes(total.Weighted))},
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Estimated cost*\n%s", formatCost(total.CostUSD))},
+	}
+	if r.Forecast != nil {
+		forecast := formatCost(r.Pricing.cost(r.Forecast.Linear))
+		fields = append(fields, slackText{Type: "mrkdwn", Text: fmt.Sprintf("*Forecast*\n%s by %s", forecast, r.Forecast.CycleEnd.Format(time.DateOnly))})
+	}
+
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: title}},
+		{Type: "section", Fields: fields},
+	}
+	if table := slackWorkflowTable(data); table != "" {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: table}})
+	}
+
+	elements := []slackText{{Type: "mrkdwn", Text: fmt.Sprintf("Generated at %s", r.GeneratedAt.Format("2006-01-02 15:04 MST"))}}
+	if len(r.Failures) > 0 {
+		elements = append(elements, slackText{Type: "mrkdwn", Text: fmt.Sprintf(":warning: %d workflows or repositories could not be fetched", len(r.Failures))})
+	}
+	blocks = append(blocks, slackBlock{Type: "context", Elements: elements})
+
+	return slackMessage{
+		Text:   fmt.Sprintf("%s: %s (%s weighted min)", title, formatCost(total.CostUSD), formatMinutes(total.Weighted)),
+		Blocks: blocks,
+	}
+}
+
+// slackWorkflowTable formats the workflows with the highest cost as a preformatted table, as Block Kit has no table block.
+// The workflow names are prefixed with the repository name in the reports of several repositories.
+func slackWorkflowTable(data TemplateData) string {
+	type row struct {
+		name string
+		TemplateBillableTime
+	}
+	var rows []row
+	for _, repository := range data.Repositories {
+		for _, workflow := range repository.Workflows {
+			name := workflow.DisplayName
+			if data.MultiRepository {
+				name = fmt.Sprintf("%s: %s", strings.TrimPrefix(repository.Name, data.Organization+"/"), name)
+			}
+			rows = append(rows, row{name: name, TemplateBillableTime: workflow.TemplateBillableTime})
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].CostUSD > rows[j].CostUSD
+	})
+
+	n := min(len(rows), notifyTopWorkflows)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*Top %d of %d workflows*\n```\n", n, len(rows)))
+	sb.WriteString(fmt.Sprintf("%-*s %14s %10s\n", slackNameWidth, "Workflow", "Weighted (min)", "Cost (USD)"))
+	for _, row := range rows[:n] {
+		name := row.name
+		if runes := []rune(name); len(runes) > slackNameWidth {
+			name = string(runes[:slackNameWidth-1]) + "…"
+		}
+		sb.WriteString(fmt.Sprintf("%-*s %14s %10s\n", slackNameWidth, name, formatMinutes(row.Weighted), formatCost(row.CostUSD)))
+	}
+	sb.WriteString("```")
+	return sb.String()
+}
@@ -0,0 +1,170 @@
+package bills
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestNotifier creates a notifier retrying the failed requests without waiting
+func newTestNotifier(dryRun bool, out io.Writer) notifier {
+	n := newNotifier(Options{MaxRetries: 2, MaxRateLimitWait: time.Minute, NotifyDryRun: dryRun}, out)
+	n.client.Transport.(*retryTransport).sleep = func(context.Context, time.Duration) error { return nil }
+	return n
+}
+
+func Test_notifier_notify(t *testing.T) {
+	report := Report{
+		Pricing:     DefaultPricing,
+		GeneratedAt: time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC),
+		Repositories: []RepositoryBillableTime{
+			{Repository: "owner/repo", Workflows: WorkflowBillableTimes{{ID: 1, Name: "CI", BillableTime: BillableTime{Ubuntu: 10}}}},
+		},
+	}
+	wantJSON, err := report.generateJSONReport()
+	if err != nil {
+		t.Fatalf("generateJSONReport() error = %v", err)
+	}
+
+	received := make(map[string][]string)
+	attempts := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts[r.URL.Path]++
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %v, want application/json", r.Header.Get("Content-Type"))
+		}
+		switch {
+		case r.URL.Path == "/slack" && attempts[r.URL.Path] == 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		case r.URL.Path == "/unavailable":
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		case r.URL.Path == "/invalid":
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received[r.URL.Path] = append(received[r.URL.Path], string(body))
+	}))
+	defer server.Close()
+
+	t.Run("send", func(t *testing.T) {
+		opts := Options{SlackWebhookURL: server.URL + "/slack", WebhookURL: server.URL + "/webhook"}
+		if err := newTestNotifier(false, io.Discard).notify(context.Background(), opts, report, "owner/repo"); err != nil {
+			t.Fatalf("notifier.notify() error = %v", err)
+		}
+
+		if attempts["/slack"] != 2 {
+			t.Errorf("Slack webhook attempts = %d, want 2", attempts["/slack"])
+		}
+		if len(received["/slack"]) != 1 {
+			t.Fatalf("Slack webhook received %d messages, want 1", len(received["/slack"]))
+		}
+		var got slackMessage
+		if err := json.Unmarshal([]byte(received["/slack"][0]), &got); err != nil {
+			t.Fatalf("failed to decode Slack message: %v", err)
+		}
+		if want := newSlackMessage(report, "owner/repo"); !reflect.DeepEqual(got, want) {
+			t.Errorf("Slack message = %v, want %v", got, want)
+		}
+		if !reflect.DeepEqual(received["/webhook"], []string{wantJSON}) {
+			t.Errorf("webhook received = %v, want %v", received["/webhook"], []string{wantJSON})
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		var out bytes.Buffer
+		opts := Options{WebhookURL: server.URL + "/dry-run"}
+		if err := newTestNotifier(true, &out).notify(context.Background(), opts, report, "owner/repo"); err != nil {
+			t.Fatalf("notifier.notify() error = %v", err)
+		}
+		if attempts["/dry-run"] != 0 {
+			t.Errorf("webhook attempts = %d, want 0", attempts["/dry-run"])
+		}
+		if want := "Dry run: the following payload is not sent to the webhook\n" + wantJSON + "\n"; out.String() != want {
+			t.Errorf("output = %v, want %v", out.String(), want)
+		}
+	})
+
+	t.Run("server error is not retried", func(t *testing.T) {
+		opts := Options{WebhookURL: server.URL + "/unavailable"}
+		if err := newTestNotifier(false, io.Discard).notify(context.Background(), opts, report, "owner/repo"); err == nil {
+			t.Fatal("notifier.notify() error = nil, want an error")
+		}
+		if attempts["/unavailable"] != 1 {
+			t.Errorf("webhook attempts = %d, want 1", attempts["/unavailable"])
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		opts := Options{WebhookURL: server.URL + "/invalid?token=secret"}
+		err := newTestNotifier(false, io.Discard).notify(context.Background(), opts, report, "owner/repo")
+		if err == nil {
+			t.Fatal("notifier.notify() error = nil, want an error")
+		}
+		if !strings.Contains(err.Error(), "400 Bad Request: invalid_payload") || strings.Contains(err.Error(), "secret") {
+			t.Errorf("notifier.notify() error = %v, want the status and the body without the URL", err)
+		}
+	})
+}
+
+func Test_newSlackMessage(t *testing.T) {
+	report := Report{
+		Organization: "org",
+		Pricing:      DefaultPricing,
+		GeneratedAt:  time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC),
+		Repositories: []RepositoryBillableTime{
+			{Repository: "org/app", Workflows: WorkflowBillableTimes{
+				{ID: 1, Name: "CI", BillableTime: BillableTime{Ubuntu: 100}},
+				{ID: 2, Name: "Release on every supported platform and architecture", BillableTime: BillableTime{Macos: 30}},
+			}},
+			{Repository: "org/lib", Workflows: WorkflowBillableTimes{
+				{ID: 3, Name: "Test", BillableTime: BillableTime{Windows: 20}},
+			}},
+		},
+		Previous: &Snapshot{
+			GeneratedAt: time.Date(2024, 5, 9, 9, 30, 0, 0, time.UTC),
+			Repositories: []SnapshotRepository{
+				{Repository: "org/app", Workflows: []SnapshotWorkflow{{ID: 1, Ubuntu: 90}, {ID: 2, Macos: 30}}},
+				{Repository: "org/lib", Workflows: []SnapshotWorkflow{{ID: 3, Windows: 25}}},
+			},
+		},
+		Failures: []FetchFailure{{Repository: "org/broken", Reason: "Not Found"}},
+	}
+	want := slackMessage{
+		Text: "Actions billable time for org: $3.52 (440 weighted min)",
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: "Actions billable time for org"}},
+			{Type: "section", Fields: []slackText{
+				{Type: "mrkdwn", Text: "*Ubuntu*\n100 min (+10)"},
+				{Type: "mrkdwn", Text: "*Windows*\n20 min (-5)"},
+				{Type: "mrkdwn", Text: "*macOS*\n30 min (0)"},
+				{Type: "mrkdwn", Text: "*Weighted*\n440 min"},
+				{Type: "mrkdwn", Text: "*Estimated cost*\n$3.52"},
+			}},
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: "*Top 3 of 3 workflows*\n```\n" +
+				"Workflow                                 Weighted (min) Cost (USD)\n" +
+				"app: Release on every supported platfor…            300      $2.40\n" +
+				"app: CI                                             100      $0.80\n" +
+				"lib: Test                                            40      $0.32\n" +
+				"```"}},
+			{Type: "context", Elements: []slackText{
+				{Type: "mrkdwn", Text: "Generated at 2024-05-10 09:30 UTC"},
+				{Type: "mrkdwn", Text: ":warning: 1 workflows or repositories could not be fetched"},
+			}},
+		},
+	}
+
+	if got := newSlackMessage(report, "org"); !reflect.DeepEqual(got, want) {
+		t.Errorf("newSlackMessage() = %+v, want %+v", got, want)
+	}
+}
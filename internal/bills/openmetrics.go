@@ -0,0 +1,102 @@
+package bills
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// openMetricsContentType is the content type of the OpenMetrics text exposition
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// openMetricsLabelEscaper escapes the label values as described in the OpenMetrics specification
+var openMetricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// generateOpenMetricsReport generates an OpenMetrics text exposition of the report with a gauge for the billable time
+// of each workflow and environment, the weighted minutes and the cost of each workflow, and the totals.
+// Workflows sharing a name are labelled with their paths appended as in the markdown table, so each series is unique.
+func (r Report) generateOpenMetricsReport() (string, error) {
+	type workflowSeries struct {
+		repo     string
+		workflow string
+		BillableTime
+	}
+	var series []workflowSeries
+	for _, rbt := range r.Repositories {
+		for _, wbt := range rbt.Workflows.sortWorkflows() {
+			series = append(series, workflowSeries{repo: rbt.Repository, workflow: rbt.Workflows.displayName(wbt), BillableTime: wbt.BillableTime})
+		}
+	}
+
+	var sb strings.Builder
+	writeOpenMetricsFamily(&sb, "actbills_workflow_billable_minutes", "Billable time of the workflow in this billing cycle for each runner OS.")
+	for _, s := range series {
+		for _, env := range s.environments() {
+			writeOpenMetricsSample(&sb, "actbills_workflow_billable_minutes", strconv.FormatInt(env.minutes, 10), "repo", s.repo, "workflow", s.workflow, "os", env.os)
+		}
+	}
+	writeOpenMetricsFamily(&sb, "actbills_workflow_weighted_minutes", "Billable time of the workflow in this billing cycle with the minute multiplier of each runner OS applied.")
+	for _, s := range series {
+		writeOpenMetricsSample(&sb, "actbills_workflow_weighted_minutes", formatMinutes(r.Pricing.weightedMinutes(s.BillableTime)), "repo", s.repo, "workflow", s.workflow)
+	}
+	writeOpenMetricsFamily(&sb, "actbills_workflow_cost_usd", "Estimated cost of the workflow in this billing cycle in USD.")
+	for _, s := range series {
+		writeOpenMetricsSample(&sb, "actbills_workflow_cost_usd", formatOpenMetricsCost(r.Pricing.cost(s.BillableTime)), "repo", s.repo, "workflow", s.workflow)
+	}
+
+	total := r.calculateTotal()
+	writeOpenMetricsFamily(&sb, "actbills_billable_minutes", "Total billable time of the report in this billing cycle for each runner OS.")
+	for _, env := range total.environments() {
+		writeOpenMetricsSample(&sb, "actbills_billable_minutes", strconv.FormatInt(env.minutes, 10), "os", env.os)
+	}
+	writeOpenMetricsFamily(&sb, "actbills_weighted_minutes", "Total billable time of the report in this billing cycle with the minute multipliers applied.")
+	writeOpenMetricsSample(&sb, "actbills_weighted_minutes", formatMinutes(r.Pricing.weightedMinutes(total)))
+	writeOpenMetricsFamily(&sb, "actbills_cost_usd", "Total estimated cost of the report in this billing cycle in USD.")
+	writeOpenMetricsSample(&sb, "actbills_cost_usd", formatOpenMetricsCost(r.Pricing.cost(total)))
+
+	writeOpenMetricsFamily(&sb, "actbills_fetch_failures", "Number of the workflows and repositories that could not be fetched.")
+	writeOpenMetricsSample(&sb, "actbills_fetch_failures", strconv.Itoa(len(r.Failures)))
+	writeOpenMetricsFamily(&sb, "actbills_report_generated_timestamp_seconds", "Time the report was generated in seconds since the Unix epoch.")
+	writeOpenMetricsSample(&sb, "actbills_report_generated_timestamp_seconds", strconv.FormatInt(r.GeneratedAt.Unix(), 10))
+
+	sb.WriteString("# EOF\n")
+	return sb.String(), nil
+}
+
+// openMetricsEnvironment represents the billable time of a runner OS labelled in the OpenMetrics exposition
+type openMetricsEnvironment struct {
+	os      string
+	minutes int64
+}
+
+// environments returns the billable time of each runner OS with the label values of the OpenMetrics exposition
+func (e BillableTime) environments() []openMetricsEnvironment {
+	return []openMetricsEnvironment{
+		{os: "ubuntu", minutes: e.Ubuntu},
+		{os: "windows", minutes: e.Windows},
+		{os: "macos", minutes: e.Macos},
+	}
+}
+
+// writeOpenMetricsFamily writes the metadata of a gauge metric family
+func writeOpenMetricsFamily(sb *strings.Builder, name, help string) {
+	sb.WriteString(fmt.Sprintf("# TYPE %s gauge\n# HELP %s %s\n", name, name, help))
+}
+
+// writeOpenMetricsSample writes a sample of the metric with the labels given as name and value pairs
+func writeOpenMetricsSample(sb *strings.Builder, name, value string, labels ...string) {
+	sb.WriteString(name)
+	if len(labels) > 0 {
+		pairs := make([]string, 0, len(labels)/2)
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], openMetricsLabelEscaper.Replace(labels[i+1])))
+		}
+		sb.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	sb.WriteString(" " + value + "\n")
+}
+
+// formatOpenMetricsCost formats the cost in USD rounded to cents without the currency symbol (e.g. 1.23)
+func formatOpenMetricsCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', 2, 64)
+}
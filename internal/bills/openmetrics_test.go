@@ -0,0 +1,72 @@
+package bills
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReport_generateOpenMetricsReport(t *testing.T) {
+	r := Report{
+		Pricing:     DefaultPricing,
+		GeneratedAt: time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC),
+		Repositories: []RepositoryBillableTime{
+			{
+				Repository: "owner/repo",
+				Workflows: WorkflowBillableTimes{
+					{ID: 1, Name: "CI", Path: ".github/workflows/ci.yml", BillableTime: BillableTime{Ubuntu: 100, Windows: 10}},
+					{ID: 2, Name: "CI", Path: ".github/workflows/ci-mac.yml", BillableTime: BillableTime{Macos: 3}},
+					{ID: 3, Name: `Say "hello"`, Path: ".github/workflows/hello.yml"},
+				},
+			},
+		},
+		Failures: []FetchFailure{{Repository: "owner/repo", WorkflowID: 4, Reason: "Not Found"}},
+	}
+	want := `# TYPE actbills_workflow_billable_minutes gauge
+# HELP actbills_workflow_billable_minutes Billable time of the workflow in this billing cycle for each runner OS.
+actbills_workflow_billable_minutes{repo="owner/repo",workflow="CI (.github/workflows/ci-mac.yml)",os="ubuntu"} 0
+actbills_workflow_billable_minutes{repo="owner/repo",workflow="CI (.github/workflows/ci-mac.yml)",os="windows"} 0
+actbills_workflow_billable_minutes{repo="owner/repo",workflow="CI (.github/workflows/ci-mac.yml)",os="macos"} 3
+actbills_workflow_billable_minutes{repo="owner/repo",workflow="CI (.github/workflows/ci.yml)",os="ubuntu"} 100
+actbills_workflow_billable_minutes{repo="owner/repo",workflow="CI (.github/workflows/ci.yml)",os="windows"} 10
+actbills_workflow_billable_minutes{repo="owner/repo",workflow="CI (.github/workflows/ci.yml)",os="macos"} 0
+actbills_workflow_billable_minutes{repo="owner/repo",workflow="Say \"hello\"",os="ubuntu"} 0
+actbills_workflow_billable_minutes{repo="owner/repo",workflow="Say \"hello\"",os="windows"} 0
+actbills_workflow_billable_minutes{repo="owner/repo",workflow="Say \"hello\"",os="macos"} 0
+# TYPE actbills_workflow_weighted_minutes gauge
+# HELP actbills_workflow_weighted_minutes Billable time of the workflow in this billing cycle with the minute multiplier of each runner OS applied.
+actbills_workflow_weighted_minutes{repo="owner/repo",workflow="CI (.github/workflows/ci-mac.yml)"} 30
+actbills_workflow_weighted_minutes{repo="owner/repo",workflow="CI (.github/workflows/ci.yml)"} 120
+actbills_workflow_weighted_minutes{repo="owner/repo",workflow="Say \"hello\""} 0
+# TYPE actbills_workflow_cost_usd gauge
+# HELP actbills_workflow_cost_usd Estimated cost of the workflow in this billing cycle in USD.
+actbills_workflow_cost_usd{repo="owner/repo",workflow="CI (.github/workflows/ci-mac.yml)"} 0.24
+actbills_workflow_cost_usd{repo="owner/repo",workflow="CI (.github/workflows/ci.yml)"} 0.96
+actbills_workflow_cost_usd{repo="owner/repo",workflow="Say \"hello\""} 0.00
+# TYPE actbills_billable_minutes gauge
+# HELP actbills_billable_minutes Total billable time of the report in this billing cycle for each runner OS.
+actbills_billable_minutes{os="ubuntu"} 100
+actbills_billable_minutes{os="windows"} 10
+actbills_billable_minutes{os="macos"} 3
+# TYPE actbills_weighted_minutes gauge
+# HELP actbills_weighted_minutes Total billable time of the report in this billing cycle with the minute multipliers applied.
+actbills_weighted_minutes 150
+# TYPE actbills_cost_usd gauge
+# HELP actbills_cost_usd Total estimated cost of the report in this billing cycle in USD.
+actbills_cost_usd 1.20
+# TYPE actbills_fetch_failures gauge
+# HELP actbills_fetch_failures Number of the workflows and repositories that could not be fetched.
+actbills_fetch_failures 1
+# TYPE actbills_report_generated_timestamp_seconds gauge
+# HELP actbills_report_generated_timestamp_seconds Time the report was generated in seconds since the Unix epoch.
+actbills_report_generated_timestamp_seconds 1715333400
+# EOF
+`
+
+	got, err := r.generateOpenMetricsReport()
+	if err != nil {
+		t.Fatalf("Report.generateOpenMetricsReport() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Report.generateOpenMetricsReport() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package bills
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// actionOutput represents an output of the action for the following steps
+type actionOutput struct {
+	name  string
+	value string
+}
+
+// newActionOutputs returns the outputs of the action with the key results of the report.
+// The top workflow is the one with the highest cost, or empty if the report has no workflows.
+func newActionOutputs(r Report, jsonReportPath string) []actionOutput {
+	data := newTemplateData(r)
+
+	var top *TemplateWorkflow
+	var topRepository string
+	for _, repository := range data.Repositories {
+		for i, workflow := range repository.Workflows {
+			if top == nil || workflow.CostUSD > top.CostUSD {
+				top = &repository.Workflows[i]
+				topRepository = repository.Name
+			}
+		}
+	}
+	var topWorkflow string
+	if top != nil {
+		topWorkflow = top.DisplayName
+	}
+
+	return []actionOutput{
+		{name: "ubuntu_minutes", value: strconv.FormatInt(data.Total.Ubuntu, 10)},
+		{name: "windows_minutes", value: strconv.FormatInt(data.Total.Windows, 10)},
+		{name: "macos_minutes", value: strconv.FormatInt(data.Total.Macos, 10)},
+		{name: "weighted_minutes", value: formatMinutes(data.Total.Weighted)},
+		{name: "cost_usd", value: strconv.FormatFloat(data.Total.CostUSD, 'f', 2, 64)},
+		{name: "json_report", value: jsonReportPath},
+		{name: "top_workflow", value: topWorkflow},
+		{name: "top_workflow_repository", value: topRepository},
+	}
+}
+
+// formatActionOutputs formats the outputs with the multiline syntax of $GITHUB_OUTPUT, delimited by the delimiter
+func formatActionOutputs(outputs []actionOutput, delimiter string) (string, error) {
+	var sb strings.Builder
+	for _, o := range outputs {
+		if strings.Contains(o.value, delimiter) {
+			return "", fmt.Errorf("output %s contains the delimiter %s", o.name, delimiter)
+		}
+		sb.WriteString(fmt.Sprintf("%s<<%s\n%s\n%s\n", o.name, delimiter, o.value, delimiter))
+	}
+	return sb.String(), nil
+}
+
+// newOutputDelimiter returns a random delimiter of the outputs, so no value can end the output early
+func newOutputDelimiter() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate output delimiter: %w", err)
+	}
+	return "ghadelimiter_" + hex.EncodeToString(b), nil
+}
+
+// writeActionOutputs appends the outputs of the action with the key results of the report to $GITHUB_OUTPUT.
+// It does nothing if $GITHUB_OUTPUT is not set, i.e. outside GitHub Actions.
+func writeActionOutputs(r Report, jsonReportPath string) error {
+	outputPath := os.Getenv("GITHUB_OUTPUT")
+	if outputPath == "" {
+		return nil
+	}
+
+	delimiter, err := newOutputDelimiter()
+	if err != nil {
+		return err
+	}
+	content, err := formatActionOutputs(newActionOutputs(r, jsonReportPath), delimiter)
+	if err != nil {
+		return err
+	}
+	return appendToFile(outputPath, content)
+}
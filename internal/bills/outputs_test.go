@@ -0,0 +1,126 @@
+package bills
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func Test_newActionOutputs(t *testing.T) {
+	tests := []struct {
+		name           string
+		r              Report
+		jsonReportPath string
+		want           []actionOutput
+	}{
+		{
+			name: "organization",
+			r: Report{
+				Organization: "org",
+				Pricing:      DefaultPricing,
+				Repositories: []RepositoryBillableTime{
+					{Repository: "org/app", Workflows: WorkflowBillableTimes{
+						{ID: 1, Name: "CI", Path: ".github/workflows/ci.yml", BillableTime: BillableTime{Ubuntu: 100}},
+						{ID: 2, Name: "CI", Path: ".github/workflows/ci-win.yml", BillableTime: BillableTime{Windows: 60}},
+					}},
+					{Repository: "org/lib", Workflows: WorkflowBillableTimes{
+						{ID: 3, Name: "Test", BillableTime: BillableTime{Macos: 3}},
+					}},
+				},
+			},
+			jsonReportPath: "bills.json",
+			want: []actionOutput{
+				{name: "ubuntu_minutes", value: "100"},
+				{name: "windows_minutes", value: "60"},
+				{name: "macos_minutes", value: "3"},
+				{name: "weighted_minutes", value: "250"},
+				{name: "cost_usd", value: "2.00"},
+				{name: "json_report", value: "bills.json"},
+				{name: "top_workflow", value: "CI (.github/workflows/ci-win.yml)"},
+				{name: "top_workflow_repository", value: "org/app"},
+			},
+		},
+		{
+			name: "no workflows",
+			r:    Report{Pricing: DefaultPricing, Repositories: []RepositoryBillableTime{{Repository: "owner/repo"}}},
+			want: []actionOutput{
+				{name: "ubuntu_minutes", value: "0"},
+				{name: "windows_minutes", value: "0"},
+				{name: "macos_minutes", value: "0"},
+				{name: "weighted_minutes", value: "0"},
+				{name: "cost_usd", value: "0.00"},
+				{name: "json_report", value: ""},
+				{name: "top_workflow", value: ""},
+				{name: "top_workflow_repository", value: ""},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newActionOutputs(tt.r, tt.jsonReportPath); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newActionOutputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatActionOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []actionOutput
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "basic",
+			outputs: []actionOutput{{name: "cost_usd", value: "1.20"}, {name: "top_workflow", value: "Build\nand test"}, {name: "json_report", value: ""}},
+			want:    "cost_usd<<EOF_1\n1.20\nEOF_1\ntop_workflow<<EOF_1\nBuild\nand test\nEOF_1\njson_report<<EOF_1\n\nEOF_1\n",
+		},
+		{
+			name:    "delimiter in value",
+			outputs: []actionOutput{{name: "top_workflow", value: "EOF_1"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatActionOutputs(tt.outputs, "EOF_1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatActionOutputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("formatActionOutputs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeActionOutputs(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "output")
+	if err := os.WriteFile(outputPath, []byte("previous<<EOF\nstep\nEOF\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	t.Setenv("GITHUB_OUTPUT", outputPath)
+
+	r := Report{Pricing: DefaultPricing, Repositories: []RepositoryBillableTime{
+		{Repository: "owner/repo", Workflows: WorkflowBillableTimes{{ID: 1, Name: "CI", BillableTime: BillableTime{Ubuntu: 10}}}},
+	}}
+	if err := writeActionOutputs(r, ""); err != nil {
+		t.Fatalf("writeActionOutputs() error = %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	delimiter := regexp.MustCompile(`ubuntu_minutes<<(ghadelimiter_[0-9a-f]{32})\n`).FindSubmatch(data)
+	if delimiter == nil {
+		t.Fatalf("writeActionOutputs() wrote %q, want the outputs delimited by a random delimiter", data)
+	}
+	outputs, _ := formatActionOutputs(newActionOutputs(r, ""), string(delimiter[1]))
+	if want := "previous<<EOF\nstep\nEOF\n" + outputs; string(data) != want {
+		t.Errorf("writeActionOutputs() wrote %q, want %q", data, want)
+	}
+}
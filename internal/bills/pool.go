@@ -0,0 +1,82 @@
+package bills
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultConcurrency is the default number of requests sent to the GitHub API concurrently
+const DefaultConcurrency = 4
+
+// workerPool represents a bounded number of workers running tasks concurrently
+type workerPool struct {
+	workers    int  // Maximum number of tasks run concurrently
+	collectAll bool // Run all tasks and join their errors instead of cancelling the remaining tasks on the first error
+}
+
+// newWorkerPool creates a workerPool with the specified number of workers.
+// If workers is less than 1, DefaultConcurrency is used.
+func newWorkerPool(workers int, collectAll bool) workerPool {
+	if workers < 1 {
+		workers = DefaultConcurrency
+	}
+	return workerPool{workers: workers, collectAll: collectAll}
+}
+
+// runPool calls fn for each item with the workers of the pool and returns the results in the order of the items.
+// By default, the first error cancels the context passed to the remaining tasks and is returned without the results.
+// If the pool collects all errors, all tasks are run and the errors are joined in the order of the items,
+// with the zero value as the result of each failed task.
+func runPool[T, R any](ctx context.Context, p workerPool, items []T, fn func(context.Context, T) (R, error)) ([]R, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+	var (
+		firstErr error
+		once     sync.Once
+	)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(p.workers, len(items)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i], errs[i] = fn(ctx, items[i])
+				if errs[i] != nil && !p.collectAll {
+					once.Do(func() {
+						firstErr = errs[i]
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if p.collectAll {
+		return results, errors.Join(errs...)
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// tasks are skipped without an error of their own when the parent context is cancelled
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,141 @@
+package bills
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_newWorkerPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		want    workerPool
+	}{
+		{name: "default", workers: 0, want: workerPool{workers: DefaultConcurrency}},
+		{name: "negative", workers: -1, want: workerPool{workers: DefaultConcurrency}},
+		{name: "specified", workers: 8, want: workerPool{workers: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newWorkerPool(tt.workers, false); got != tt.want {
+				t.Errorf("newWorkerPool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runPool(t *testing.T) {
+	items := []int{5, 4, 3, 2, 1, 0}
+	// later items finish first so that the results are collected out of order
+	square := func(ctx context.Context, i int) (int, error) {
+		time.Sleep(time.Duration(i) * time.Millisecond)
+		return i * i, nil
+	}
+	failing := func(ctx context.Context, i int) (int, error) {
+		if i == 3 || i == 1 {
+			return 0, fmt.Errorf("item %d failed", i)
+		}
+		return i * i, nil
+	}
+
+	tests := []struct {
+		name    string
+		pool    workerPool
+		fn      func(context.Context, int) (int, error)
+		want    []int
+		wantErr string
+	}{
+		{
+			name: "ordered",
+			pool: workerPool{workers: 3},
+			fn:   square,
+			want: []int{25, 16, 9, 4, 1, 0},
+		},
+		{
+			name: "more workers than items",
+			pool: workerPool{workers: 10},
+			fn:   square,
+			want: []int{25, 16, 9, 4, 1, 0},
+		},
+		{
+			name:    "first error",
+			pool:    workerPool{workers: 1},
+			fn:      failing,
+			want:    nil,
+			wantErr: "item 3 failed",
+		},
+		{
+			name:    "collect all errors",
+			pool:    workerPool{workers: 3, collectAll: true},
+			fn:      failing,
+			want:    []int{25, 16, 0, 4, 0, 0},
+			wantErr: "item 3 failed\nitem 1 failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runPool(context.Background(), tt.pool, items, tt.fn)
+			if (err != nil) != (tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("runPool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runPool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runPool_bounded(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	_, err := runPool(context.Background(), workerPool{workers: 2}, make([]int, 10), func(ctx context.Context, _ int) (int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return 0, nil
+	})
+	if err != nil {
+		t.Fatalf("runPool() error = %v", err)
+	}
+	if got := maxRunning.Load(); got > 2 {
+		t.Errorf("runPool() ran %d tasks concurrently, want at most 2", got)
+	}
+}
+
+func Test_runPool_cancel(t *testing.T) {
+	t.Run("first error cancels the remaining tasks", func(t *testing.T) {
+		var started atomic.Int32
+		errFailed := errors.New("failed")
+		_, err := runPool(context.Background(), workerPool{workers: 1}, make([]int, 10), func(ctx context.Context, _ int) (int, error) {
+			started.Add(1)
+			return 0, errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("runPool() error = %v, want %v", err, errFailed)
+		}
+		if got := started.Load(); got != 1 {
+			t.Errorf("runPool() started %d tasks, want 1", got)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := runPool(ctx, workerPool{workers: 2}, make([]int, 10), func(ctx context.Context, _ int) (int, error) {
+			return 0, nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("runPool() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package bills
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Pricing represents the minute multiplier and the price per minute for each environment
+type Pricing struct {
+	Ubuntu  Rate `json:"ubuntu" yaml:"ubuntu"`   // Rate for the Ubuntu environment
+	Windows Rate `json:"windows" yaml:"windows"` // Rate for the Windows environment
+	Macos   Rate `json:"macos" yaml:"macos"`     // Rate for the Mac environment
+
+	LargerRunners map[string]float64 `json:"larger_runners" yaml:"larger_runners"` // Price per minute (in USD) for each larger runner SKU
+
+	StoragePerGBMonth float64 `json:"storage_per_gb_month" yaml:"storage_per_gb_month"` // Price per GB per month (in USD) of the artifact storage
+}
+
+// Rate represents the minute multiplier and the price per minute of an environment
+type Rate struct {
+	Multiplier     float64 `json:"multiplier" yaml:"multiplier"`             // Multiplier applied to the billable minutes against the included minutes
+	PricePerMinute float64 `json:"price_per_minute" yaml:"price_per_minute"` // Price per minute (in USD)
+}
+
+// DefaultPricing is the pricing of the standard GitHub-hosted runners
+var DefaultPricing = Pricing{
+	Ubuntu:  Rate{Multiplier: 1, PricePerMinute: 0.008},
+	Windows: Rate{Multiplier: 2, PricePerMinute: 0.016},
+	Macos:   Rate{Multiplier: 10, PricePerMinute: 0.08},
+	LargerRunners: map[string]float64{
+		"linux-4-core":     0.016,
+		"linux-8-core":     0.032,
+		"linux-16-core":    0.064,
+		"linux-32-core":    0.128,
+		"linux-64-core":    0.256,
+		"linux-4-core-gpu": 0.07,
+		"windows-4-core":   0.032,
+		"windows-8-core":   0.064,
+		"windows-16-core":  0.128,
+		"windows-32-core":  0.256,
+		"windows-64-core":  0.512,
+		"macos-12-core":    0.12,
+		"macos-6-core-arm": 0.16,
+	},
+	StoragePerGBMonth: 0.25,
+}
+
+// defaultPricing returns a copy of DefaultPricing, so that overriding the copy does not modify DefaultPricing
+func defaultPricing() Pricing {
+	pricing := DefaultPricing
+	pricing.LargerRunners = make(map[string]float64, len(DefaultPricing.LargerRunners))
+	for sku, price := range DefaultPricing.LargerRunners {
+		pricing.LargerRunners[sku] = price
+	}
+	return pricing
+}
+
+// setDefaults sets the pricing to the default pricing, so that the configuration file overrides only the keys it sets
+func (p *Pricing) setDefaults() {
+	*p = defaultPricing()
+}
+
+// resolvePricing returns opts.Pricing if set, or the pricing loaded from opts.PricingFile
+func resolvePricing(opts Options) (Pricing, error) {
+	if opts.Pricing != nil {
+		return *opts.Pricing, nil
+	}
+	return loadPricing(opts.PricingFile)
+}
+
+// loadPricing loads the pricing from the JSON file specified by the filePath.
+// Values not specified in the file fall back to DefaultPricing.
+// If the filePath is empty, DefaultPricing is returned.
+func loadPricing(filePath string) (Pricing, error) {
+	pricing := defaultPricing()
+	if filePath == "" {
+		return pricing, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Pricing{}, fmt.Errorf("failed to read pricing file %s: %w", filePath, err)
+	}
+	if err := json.Unmarshal(data, &pricing); err != nil {
+		return Pricing{}, fmt.Errorf("failed to parse pricing file %s: %w", filePath, err)
+	}
+	if err := pricing.validate(); err != nil {
+		return Pricing{}, fmt.Errorf("invalid pricing file %s: %w", filePath, err)
+	}
+
+	return pricing, nil
+}
+
+// validate returns an error if any multiplier or price is negative
+func (p Pricing) validate() error {
+	rates := map[string]Rate{"ubuntu": p.Ubuntu, "windows": p.Windows, "macos": p.Macos}
+	for _, env := range []string{"ubuntu", "windows", "macos"} {
+		if rates[env].Multiplier < 0 {
+			return fmt.Errorf("%s.multiplier must not be negative", env)
+		}
+		if rates[env].PricePerMinute < 0 {
+			return fmt.Errorf("%s.price_per_minute must not be negative", env)
+		}
+	}
+	if p.StoragePerGBMonth < 0 {
+		return fmt.Errorf("storage_per_gb_month must not be negative")
+	}
+	for sku, price := range p.LargerRunners {
+		if price < 0 {
+			return fmt.Errorf("larger_runners.%s must not be negative", sku)
+		}
+	}
+	return nil
+}
+
+// weightedMinutes calculates the billable minutes with the multiplier of each environment applied
+func (p Pricing) weightedMinutes(b BillableTime) float64 {
+	return float64(b.Ubuntu)*p.Ubuntu.Multiplier +
+		float64(b.Windows)*p.Windows.Multiplier +
+		float64(b.Macos)*p.Macos.Multiplier
+}
+
+// cost calculates the estimated cost (in USD) of the billable time
+func (p Pricing) cost(b BillableTime) float64 {
+	return float64(b.Ubuntu)*p.Ubuntu.PricePerMinute +
+		float64(b.Windows)*p.Windows.PricePerMinute +
+		float64(b.Macos)*p.Macos.PricePerMinute
+}
+
+// storageCost calculates the estimated cost (in USD) of storing the bytes for a month
+func (p Pricing) storageCost(bytes int64) float64 {
+	return float64(bytes) / bytesPerGB * p.StoragePerGBMonth
+}
+
+// formatMinutes formats minutes without trailing zeros (e.g. 12, 1.5)
+func formatMinutes(minutes float64) string {
+	return strconv.FormatFloat(minutes, 'f', -1, 64)
+}
+
+// formatCost formats the cost in USD (e.g. $1.23)
+func formatCost(cost float64) string {
+	return fmt.Sprintf("$%.2f", cost)
+}
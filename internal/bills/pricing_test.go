@@ -0,0 +1,149 @@
+package bills
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_loadPricing(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		filePath string
+		want     Pricing
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			filePath: "",
+			want:     DefaultPricing,
+			wantErr:  false,
+		},
+		{
+			name:     "override",
+			filePath: writeFile("override.json", `{"ubuntu": {"price_per_minute": 0.004}, "macos": {"multiplier": 5, "price_per_minute": 0.05}, "larger_runners": {"linux-4-core": 0.01, "linux-2-core-arm": 0.005}}`),
+			want: Pricing{
+				Ubuntu:        Rate{Multiplier: 1, PricePerMinute: 0.004},
+				Windows:       Rate{Multiplier: 2, PricePerMinute: 0.016},
+				Macos:         Rate{Multiplier: 5, PricePerMinute: 0.05},
+				LargerRunners: withLargerRunnerPrices(map[string]float64{"linux-4-core": 0.01, "linux-2-core-arm": 0.005}),
+
+				StoragePerGBMonth: 0.25,
+			},
+			wantErr: false,
+		},
+		{
+			name:     "negative",
+			filePath: writeFile("negative.json", `{"windows": {"multiplier": -1}}`),
+			want:     Pricing{},
+			wantErr:  true,
+		},
+		{
+			name:     "negative larger runner",
+			filePath: writeFile("negative-larger.json", `{"larger_runners": {"linux-4-core": -0.01}}`),
+			want:     Pricing{},
+			wantErr:  true,
+		},
+		{
+			name:     "storage",
+			filePath: writeFile("storage.json", `{"storage_per_gb_month": 0.24}`),
+			want: Pricing{
+				Ubuntu:        DefaultPricing.Ubuntu,
+				Windows:       DefaultPricing.Windows,
+				Macos:         DefaultPricing.Macos,
+				LargerRunners: DefaultPricing.LargerRunners,
+
+				StoragePerGBMonth: 0.24,
+			},
+			wantErr: false,
+		},
+		{
+			name:     "negative storage",
+			filePath: writeFile("negative-storage.json", `{"storage_per_gb_month": -1}`),
+			want:     Pricing{},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid json",
+			filePath: writeFile("invalid.json", `{"ubuntu":`),
+			want:     Pricing{},
+			wantErr:  true,
+		},
+		{
+			name:     "not found",
+			filePath: filepath.Join(tempDir, "notfound.json"),
+			want:     Pricing{},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadPricing(tt.filePath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadPricing() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadPricing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if DefaultPricing.LargerRunners["linux-4-core"] != 0.016 {
+		t.Errorf("loadPricing() modified DefaultPricing")
+	}
+}
+
+// withLargerRunnerPrices returns a copy of the default larger runner prices with the given prices applied
+func withLargerRunnerPrices(prices map[string]float64) map[string]float64 {
+	merged := make(map[string]float64)
+	for sku, price := range DefaultPricing.LargerRunners {
+		merged[sku] = price
+	}
+	for sku, price := range prices {
+		merged[sku] = price
+	}
+	return merged
+}
+
+func TestPricing_weightedMinutesAndCost(t *testing.T) {
+	tests := []struct {
+		name         string
+		b            BillableTime
+		wantWeighted float64
+		wantCost     string
+	}{
+		{
+			name:         "basic",
+			b:            BillableTime{Ubuntu: 100, Windows: 10, Macos: 1},
+			wantWeighted: 130,
+			wantCost:     "$1.04",
+		},
+		{
+			name:         "zero",
+			b:            BillableTime{},
+			wantWeighted: 0,
+			wantCost:     "$0.00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultPricing.weightedMinutes(tt.b); got != tt.wantWeighted {
+				t.Errorf("Pricing.weightedMinutes() = %v, want %v", got, tt.wantWeighted)
+			}
+			if got := formatCost(DefaultPricing.cost(tt.b)); got != tt.wantCost {
+				t.Errorf("Pricing.cost() = %v, want %v", got, tt.wantCost)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package bills
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+const (
+	// maxIssueBodyLength is the maximum number of characters GitHub accepts in the body of an issue or a comment
+	maxIssueBodyLength = 65536
+
+	truncatedNote = "\n_The report is truncated as it exceeds the maximum length of a GitHub comment._\n"
+)
+
+// publishReport publishes the markdown report to the repository returned by publishTarget
+// as a comment on the issue or pull request opts.CommentOn, and as the tracking issue of the billing cycle if opts.TrackingIssue is set.
+func publishReport(ctx context.Context, client *github.Client, opts Options, r Report, markdown string) error {
+	owner, repo, err := publishTarget(opts)
+	if err != nil {
+		return err
+	}
+	subject, err := reportSubject(opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.CommentOn != 0 {
+		if _, err := publishComment(ctx, client, owner, repo, opts.CommentOn, subject, markdown); err != nil {
+			return err
+		}
+	}
+	if opts.TrackingIssue {
+		cycleStart := billingCycleStart(r.GeneratedAt, opts.BillingCycleDay)
+		if _, err := publishTrackingIssue(ctx, client, owner, repo, subject, cycleStart, markdown); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishTarget returns the owner and the name of the repository the report is published to:
+// opts.PublishRepository if set, otherwise the repository of the report, or $GITHUB_REPOSITORY for a report of several repositories.
+func publishTarget(opts Options) (string, string, error) {
+	target := opts.PublishRepository
+	if target == "" && opts.Organization == "" && len(opts.Repositories) == 0 {
+		target = opts.Repository
+	}
+	owner, repo, err := extractOwnerAndRepo(target)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to determine the repository to publish the report to: %w", err)
+	}
+	return owner, repo, nil
+}
+
+// reportSubject returns the subject of the report: the organization name, the listed repositories separated by commas,
+// or the repository name in owner/repo format
+func reportSubject(opts Options) (string, error) {
+	if opts.Organization != "" {
+		return opts.Organization, nil
+	}
+	if len(opts.Repositories) > 0 {
+		return strings.Join(opts.Repositories, ", "), nil
+	}
+	owner, repo, err := extractOwnerAndRepo(opts.Repository)
+	if err != nil {
+		return "", err
+	}
+	return owner + "/" + repo, nil
+}
+
+// commentMarker returns the hidden marker identifying the comment with the report of the subject (repository or organization)
+func commentMarker(subject string) string {
+	return fmt.Sprintf("<!-- actbills report: %s -->", subject)
+}
+
+// trackingIssueMarkerPrefix returns the prefix of the hidden markers identifying the tracking issues of the subject
+func trackingIssueMarkerPrefix(subject string) string {
+	return fmt.Sprintf("<!-- actbills tracking issue: %s ", subject)
+}
+
+// trackingIssueMarker returns the hidden marker identifying the tracking issue of the subject for the billing cycle starting at the cycleStart
+func trackingIssueMarker(subject string, cycleStart time.Time) string {
+	return trackingIssueMarkerPrefix(subject) + cycleStart.Format(time.DateOnly) + " -->"
+}
+
+// trackingIssueTitle returns the title of the tracking issue of the subject for the billing cycle starting at the cycleStart
+func trackingIssueTitle(subject string, cycleStart time.Time) string {
+	return fmt.Sprintf("Actions billable time for %s (%s)", subject, cycleStart.Format("January 2006"))
+}
+
+// newIssueBody returns the body of the issue or comment with the marker followed by the report.
+// The report is truncated at a line boundary if the body exceeds the maximum length GitHub accepts.
+func newIssueBody(marker, report string) string {
+	body := marker + "\n" + report
+	if len(body) <= maxIssueBodyLength {
+		return body
+	}
+	body = body[:maxIssueBodyLength-len(truncatedNote)]
+	if i := strings.LastIndex(body, "\n"); i >= 0 {
+		body = body[:i+1]
+	}
+	return body + truncatedNote
+}
+
+// publishComment creates a comment with the report of the subject on the issue or pull request,
+// or updates the comment with the report of the subject if it already exists
+func publishComment(ctx context.Context, client *github.Client, owner, repo string, number int, subject, report string) (*github.IssueComment, error) {
+	comments, err := fetchIssueComments(ctx, client, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments of %s/%s#%d: %w", owner, repo, number, err)
+	}
+
+	marker := commentMarker(subject)
+	body := newIssueBody(marker, report)
+	for _, comment := range comments {
+		if !strings.Contains(comment.GetBody(), marker) {
+			continue
+		}
+		updated, _, err := client.Issues.EditComment(ctx, owner, repo, comment.GetID(), &github.IssueComment{Body: github.String(body)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update comment %d of %s/%s#%d: %w", comment.GetID(), owner, repo, number, err)
+		}
+		return updated, nil
+	}
+
+	created, _, err := client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: github.String(body)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment on %s/%s#%d: %w", owner, repo, number, err)
+	}
+	return created, nil
+}
+
+// publishTrackingIssue opens a tracking issue with the report of the subject for the billing cycle starting at the cycleStart,
+// or updates it if it is already open. The open tracking issues of the subject for the previous billing cycles are closed.
+func publishTrackingIssue(ctx context.Context, client *github.Client, owner, repo, subject string, cycleStart time.Time, report string) (*github.Issue, error) {
+	issues, err := fetchOpenIssues(ctx, client, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issues of %s/%s: %w", owner, repo, err)
+	}
+
+	marker := trackingIssueMarker(subject, cycleStart)
+	prefix := trackingIssueMarkerPrefix(subject)
+	body := newIssueBody(marker, report)
+	var current *github.Issue
+	for _, issue := range issues {
+		switch {
+		case strings.Contains(issue.GetBody(), marker):
+			if current == nil {
+				current = issue
+			}
+		case strings.Contains(issue.GetBody(), prefix):
+			state := &github.IssueRequest{State: github.String("closed"), StateReason: github.String("completed")}
+			if _, _, err := client.Issues.Edit(ctx, owner, repo, issue.GetNumber(), state); err != nil {
+				return nil, fmt.Errorf("failed to close issue %s/%s#%d: %w", owner, repo, issue.GetNumber(), err)
+			}
+		}
+	}
+
+	if current != nil {
+		updated, _, err := client.Issues.Edit(ctx, owner, repo, current.GetNumber(), &github.IssueRequest{Body: github.String(body)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update issue %s/%s#%d: %w", owner, repo, current.GetNumber(), err)
+		}
+		return updated, nil
+	}
+
+	request := &github.IssueRequest{Title: github.String(trackingIssueTitle(subject, cycleStart)), Body: github.String(body)}
+	created, _, err := client.Issues.Create(ctx, owner, repo, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create issue on %s/%s: %w", owner, repo, err)
+	}
+	return created, nil
+}
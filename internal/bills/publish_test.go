@@ -0,0 +1,166 @@
+package bills
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+// recordRequest returns a handler recording the method, the path and the JSON body of the requests, and responding with the response
+func recordRequest(requests *[]string, response any) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		*requests = append(*requests, fmt.Sprintf("%s %s %v", r.Method, r.URL.Path, body))
+		_, _ = rw.Write(mock.MustMarshal(response))
+	}
+}
+
+func Test_publishComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []*github.IssueComment
+		want     []string
+	}{
+		{
+			name: "create",
+			comments: []*github.IssueComment{
+				{ID: github.Int64(1), Body: github.String("LGTM")},
+				{ID: github.Int64(2), Body: github.String("<!-- actbills report: owner/other -->\n# old")},
+			},
+			want: []string{"POST /repos/owner/repo/issues/5/comments map[body:<!-- actbills report: owner/repo -->\n# report]"},
+		},
+		{
+			name: "update",
+			comments: []*github.IssueComment{
+				{ID: github.Int64(1), Body: github.String("LGTM")},
+				{ID: github.Int64(3), Body: github.String("<!-- actbills report: owner/repo -->\n# old")},
+			},
+			want: []string{"PATCH /repos/owner/repo/issues/comments/3 map[body:<!-- actbills report: owner/repo -->\n# report]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			client := github.NewClient(mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesCommentsByOwnerByRepoByIssueNumber, tt.comments),
+				mock.WithRequestMatchHandler(mock.PostReposIssuesCommentsByOwnerByRepoByIssueNumber, recordRequest(&requests, github.IssueComment{})),
+				mock.WithRequestMatchHandler(mock.PatchReposIssuesCommentsByOwnerByRepoByCommentId, recordRequest(&requests, github.IssueComment{})),
+			))
+
+			if _, err := publishComment(context.Background(), client, "owner", "repo", 5, "owner/repo", "# report"); err != nil {
+				t.Fatalf("publishComment() error = %v", err)
+			}
+			if !reflect.DeepEqual(requests, tt.want) {
+				t.Errorf("publishComment() requests = %q, want %q", requests, tt.want)
+			}
+		})
+	}
+}
+
+func Test_publishTrackingIssue(t *testing.T) {
+	cycleStart := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	issue := func(number int, body string) *github.Issue {
+		return &github.Issue{Number: github.Int(number), Body: github.String(body)}
+	}
+	tests := []struct {
+		name   string
+		issues []*github.Issue
+		want   []string
+	}{
+		{
+			name: "create",
+			issues: []*github.Issue{
+				issue(1, "<!-- actbills tracking issue: org 2024-04-01 -->\n# old"),
+				issue(2, "<!-- actbills tracking issue: org2 2024-04-01 -->\n# other organization"),
+				issue(3, "Bug report"),
+			},
+			want: []string{
+				"PATCH /repos/owner/repo/issues/1 map[state:closed state_reason:completed]",
+				"POST /repos/owner/repo/issues map[body:<!-- actbills tracking issue: org 2024-05-01 -->\n# report title:Actions billable time for org (May 2024)]",
+			},
+		},
+		{
+			name: "update",
+			issues: []*github.Issue{
+				issue(3, "Bug report"),
+				issue(4, "<!-- actbills tracking issue: org 2024-05-01 -->\n# old"),
+			},
+			want: []string{
+				"PATCH /repos/owner/repo/issues/4 map[body:<!-- actbills tracking issue: org 2024-05-01 -->\n# report]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			client := github.NewClient(mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesByOwnerByRepo, tt.issues),
+				mock.WithRequestMatchHandler(mock.PostReposIssuesByOwnerByRepo, recordRequest(&requests, github.Issue{})),
+				mock.WithRequestMatchHandler(mock.PatchReposIssuesByOwnerByRepoByIssueNumber, recordRequest(&requests, github.Issue{})),
+			))
+
+			if _, err := publishTrackingIssue(context.Background(), client, "owner", "repo", "org", cycleStart, "# report"); err != nil {
+				t.Fatalf("publishTrackingIssue() error = %v", err)
+			}
+			if !reflect.DeepEqual(requests, tt.want) {
+				t.Errorf("publishTrackingIssue() requests = %q, want %q", requests, tt.want)
+			}
+		})
+	}
+}
+
+func Test_publishTarget(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Options
+		env       string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{name: "repository", opts: Options{Repository: "owner/repo"}, env: "other/repo", wantOwner: "owner", wantRepo: "repo"},
+		{name: "publish repository", opts: Options{Repository: "owner/repo", PublishRepository: "owner/reports"}, env: "", wantOwner: "owner", wantRepo: "reports"},
+		{name: "organization", opts: Options{Organization: "org"}, env: "org/actions", wantOwner: "org", wantRepo: "actions"},
+		{name: "organization with publish repository", opts: Options{Organization: "org", PublishRepository: "org/reports"}, env: "org/actions", wantOwner: "org", wantRepo: "reports"},
+		{name: "organization without repository", opts: Options{Organization: "org"}, env: "", wantErr: true},
+		{name: "invalid publish repository", opts: Options{Organization: "org", PublishRepository: "reports"}, env: "org/actions", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_REPOSITORY", tt.env)
+			owner, repo, err := publishTarget(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("publishTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf("publishTarget() = %v, %v, want %v, %v", owner, repo, tt.wantOwner, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func Test_newIssueBody(t *testing.T) {
+	row := "| workflow | 1 | 0 | 0 | 1 | $0.01 |\n"
+	long := strings.Repeat(row, maxIssueBodyLength/len(row)+1)
+
+	if got, want := newIssueBody("<!-- marker -->", "# report\n"), "<!-- marker -->\n# report\n"; got != want {
+		t.Errorf("newIssueBody() = %v, want %v", got, want)
+	}
+
+	got := newIssueBody("<!-- marker -->", long)
+	if len(got) > maxIssueBodyLength {
+		t.Errorf("newIssueBody() length = %d, want <= %d", len(got), maxIssueBodyLength)
+	}
+	if !strings.HasSuffix(got, row+truncatedNote) {
+		t.Errorf("newIssueBody() = ...%q, want to end with a full row and the truncated note", got[len(got)-100:])
+	}
+}
@@ -0,0 +1,219 @@
+package bills
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the default number of retries of a request to the GitHub API
+	DefaultMaxRetries = 5
+	// DefaultMaxRateLimitWait is the default maximum time to wait for the primary rate limit to reset
+	DefaultMaxRateLimitWait = 5 * time.Minute
+
+	// retryBackoff is the initial delay of the exponential backoff for server errors
+	retryBackoff = time.Second
+	// retryMaxBackoff is the maximum delay of the exponential backoff for server errors
+	retryMaxBackoff = 30 * time.Second
+	// secondaryRateLimitWait is the time to wait for a secondary rate limit without a Retry-After header, as documented by GitHub
+	secondaryRateLimitWait = time.Minute
+)
+
+// retryReason represents the reason to retry a request
+type retryReason string
+
+const (
+	reasonPrimaryRateLimit   retryReason = "primary rate limit"
+	reasonSecondaryRateLimit retryReason = "secondary rate limit"
+	reasonServerError        retryReason = "server error"
+)
+
+// retryTransport is an http.RoundTripper retrying the requests failed by the rate limits of the GitHub API and by server errors.
+//   - Primary rate limit (403 or 429 with X-RateLimit-Remaining: 0): waits until X-RateLimit-Reset if it is within maxWait
+//   - Secondary rate limit (403 or 429 with Retry-After, or mentioning a secondary rate limit): waits for Retry-After (default 1 minute)
+//   - Server errors (5xx): waits with an exponential backoff and jitter, only for the idempotent methods
+//
+// A successful response exhausting the primary rate limit (X-RateLimit-Remaining: 0) makes the following requests wait until
+// X-RateLimit-Reset if it is within maxWait. Its X-RateLimit-Reset header is removed, as go-github would otherwise reject the
+// following requests by the cached rate limit without sending them to the transport.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int           // Maximum number of retries of a request (0 disables retries)
+	maxWait    time.Duration // Maximum time to wait for a rate limit before giving up
+
+	mu      sync.Mutex
+	resetAt time.Time // Time the exhausted primary rate limit resets at (zero if not exhausted)
+
+	// sleep waits for the duration or until the context is done. It is replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// newRetryTransport creates a retryTransport wrapping the base transport
+func newRetryTransport(base http.RoundTripper, maxRetries int, maxWait time.Duration) *retryTransport {
+	return &retryTransport{base: base, maxRetries: maxRetries, maxWait: maxWait, sleep: sleepContext}
+}
+
+// RoundTrip sends the request and retries it while the response is retryable and the retries are left
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.waitRateLimitReset(req); err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries {
+			return resp, err
+		}
+
+		wait, reason, ok := retryDelay(resp, attempt)
+		if !ok {
+			t.recordRateLimitReset(resp)
+			return resp, nil
+		}
+		if reason == reasonServerError && !isIdempotent(req.Method) {
+			return resp, nil // the server may have applied the request, so sending it again may duplicate it
+		}
+		if reason != reasonServerError && wait > t.maxWait {
+			log.Printf("not retrying %s %s: %s resets in %s, exceeding the maximum wait of %s", req.Method, req.URL.Path, reason, wait.Round(time.Second), t.maxWait)
+			return resp, nil
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil // the body cannot be sent again
+		}
+
+		log.Printf("retrying %s %s in %s after %s (%d/%d)", req.Method, req.URL.Path, wait.Round(time.Millisecond), reason, attempt+1, t.maxRetries)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind the request body: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// recordRateLimitReset records the reset time of the primary rate limit exhausted by the successful response if it is within maxWait,
+// and removes the X-RateLimit-Reset header so go-github sends the following requests to the transport, which waits for the reset.
+func (t *retryTransport) recordRateLimitReset(resp *http.Response) {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || time.Until(time.Unix(reset, 0)) > t.maxWait {
+		return // go-github rejects the following requests until the reset
+	}
+
+	t.mu.Lock()
+	t.resetAt = time.Unix(reset, 0)
+	t.mu.Unlock()
+	resp.Header.Del("X-RateLimit-Reset")
+}
+
+// waitRateLimitReset waits until the primary rate limit exhausted by a previous response resets
+func (t *retryTransport) waitRateLimitReset(req *http.Request) error {
+	t.mu.Lock()
+	resetAt := t.resetAt
+	t.mu.Unlock()
+	if resetAt.IsZero() {
+		return nil
+	}
+
+	// wait a second more as the reset time is rounded down to the second
+	if wait := time.Until(resetAt) + time.Second; wait > 0 {
+		log.Printf("waiting %s for the %s to reset before %s %s", wait.Round(time.Second), reasonPrimaryRateLimit, req.Method, req.URL.Path)
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	if t.resetAt.Equal(resetAt) {
+		t.resetAt = time.Time{}
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+// retryDelay returns the time to wait before retrying the request and the reason if the response is retryable
+func retryDelay(resp *http.Response, attempt int) (time.Duration, retryReason, bool) {
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+			seconds, err := strconv.Atoi(retryAfter)
+			if err != nil {
+				return 0, "", false
+			}
+			return time.Duration(seconds) * time.Second, reasonSecondaryRateLimit, true
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+			if err != nil {
+				return 0, "", false
+			}
+			// wait a second more as the reset time is rounded down to the second
+			return max(time.Until(time.Unix(reset, 0))+time.Second, 0), reasonPrimaryRateLimit, true
+		}
+		if isSecondaryRateLimit(resp) {
+			return secondaryRateLimitWait, reasonSecondaryRateLimit, true
+		}
+		return 0, "", false
+	case resp.StatusCode >= 500:
+		backoff := retryMaxBackoff
+		if attempt < 5 { // avoid overflowing the shift
+			backoff = min(retryBackoff<<attempt, retryMaxBackoff)
+		}
+		// jitter between half and the full backoff
+		return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)), reasonServerError, true
+	default:
+		return 0, "", false
+	}
+}
+
+// isIdempotent returns true if sending the request of the method twice has the same effect as sending it once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// isSecondaryRateLimit returns true if the body of the response mentions a secondary rate limit.
+// The body is restored so it can be read again.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+// sleepContext waits for the duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,277 @@
+package bills
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// response represents a response of the test server
+type response struct {
+	status  int
+	headers map[string]string
+	body    string
+}
+
+// newSequenceServer creates a test server responding with the responses in order, repeating the last one.
+// The bodies of the requests received are appended to the bodies.
+func newSequenceServer(t *testing.T, responses []response, bodies *[]string) *httptest.Server {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*bodies = append(*bodies, string(body))
+		res := responses[min(requests, len(responses)-1)]
+		requests++
+		for key, value := range res.headers {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(res.status)
+		_, _ = w.Write([]byte(res.body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_retryTransport(t *testing.T) {
+	reset := func(d time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+	}
+	ok := response{status: http.StatusOK, body: "{}"}
+
+	tests := []struct {
+		name         string
+		method       string // method of the request (default POST)
+		responses    []response
+		maxRetries   int
+		wantRequests int
+		wantStatus   int
+		wantWaits    []time.Duration // approximate waits before each retry
+	}{
+		{
+			name:         "success",
+			responses:    []response{ok},
+			maxRetries:   3,
+			wantRequests: 1,
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:         "server error",
+			method:       http.MethodPut,
+			responses:    []response{{status: http.StatusBadGateway}, {status: http.StatusServiceUnavailable}, ok},
+			maxRetries:   3,
+			wantRequests: 3,
+			wantStatus:   http.StatusOK,
+			wantWaits:    []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:         "retries exhausted",
+			method:       http.MethodPut,
+			responses:    []response{{status: http.StatusInternalServerError}},
+			maxRetries:   2,
+			wantRequests: 3,
+			wantStatus:   http.StatusInternalServerError,
+			wantWaits:    []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:         "retries disabled",
+			method:       http.MethodPut,
+			responses:    []response{{status: http.StatusInternalServerError}},
+			maxRetries:   0,
+			wantRequests: 1,
+			wantStatus:   http.StatusInternalServerError,
+		},
+		{
+			name:         "server error on POST",
+			responses:    []response{{status: http.StatusBadGateway}, ok},
+			maxRetries:   3,
+			wantRequests: 1,
+			wantStatus:   http.StatusBadGateway,
+		},
+		{
+			name:         "server error on PATCH",
+			method:       http.MethodPatch,
+			responses:    []response{{status: http.StatusInternalServerError}, ok},
+			maxRetries:   3,
+			wantRequests: 1,
+			wantStatus:   http.StatusInternalServerError,
+		},
+		{
+			name: "primary rate limit",
+			responses: []response{
+				{status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset(30 * time.Second)}},
+				ok,
+			},
+			maxRetries:   3,
+			wantRequests: 2,
+			wantStatus:   http.StatusOK,
+			wantWaits:    []time.Duration{31 * time.Second},
+		},
+		{
+			name: "primary rate limit over the maximum wait",
+			responses: []response{
+				{status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset(time.Hour)}},
+				ok,
+			},
+			maxRetries:   3,
+			wantRequests: 1,
+			wantStatus:   http.StatusForbidden,
+		},
+		{
+			name: "secondary rate limit",
+			responses: []response{
+				{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "7"}},
+				ok,
+			},
+			maxRetries:   3,
+			wantRequests: 2,
+			wantStatus:   http.StatusOK,
+			wantWaits:    []time.Duration{7 * time.Second},
+		},
+		{
+			name: "secondary rate limit without Retry-After",
+			responses: []response{
+				{status: http.StatusForbidden, body: `{"message": "You have exceeded a secondary rate limit."}`},
+				ok,
+			},
+			maxRetries:   3,
+			wantRequests: 2,
+			wantStatus:   http.StatusOK,
+			wantWaits:    []time.Duration{time.Minute},
+		},
+		{
+			name:         "forbidden",
+			responses:    []response{{status: http.StatusForbidden, body: `{"message": "Resource not accessible by integration"}`}},
+			maxRetries:   3,
+			wantRequests: 1,
+			wantStatus:   http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			server := newSequenceServer(t, tt.responses, &bodies)
+			var waits []time.Duration
+			transport := newRetryTransport(http.DefaultTransport, tt.maxRetries, time.Minute)
+			transport.sleep = func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req, err := http.NewRequest(method, server.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatalf("http.NewRequest() error = %v", err)
+			}
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("retryTransport.RoundTrip() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus || len(bodies) != tt.wantRequests {
+				t.Errorf("retryTransport.RoundTrip() = %d after %d requests, want %d after %d requests", resp.StatusCode, len(bodies), tt.wantStatus, tt.wantRequests)
+			}
+			for i, body := range bodies {
+				if body != "payload" {
+					t.Errorf("request %d body = %q, want %q", i, body, "payload")
+				}
+			}
+			if len(waits) != len(tt.wantWaits) {
+				t.Fatalf("retryTransport.RoundTrip() waited %v, want %v", waits, tt.wantWaits)
+			}
+			for i, want := range tt.wantWaits {
+				// allow the jitter of the backoff and the rounding of the rate limit reset
+				if waits[i] < want/2-time.Second || waits[i] > want {
+					t.Errorf("retryTransport.RoundTrip() waited %v, want about %v", waits[i], want)
+				}
+			}
+		})
+	}
+}
+
+func Test_retryTransport_cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest() error = %v", err)
+	}
+	transport := newRetryTransport(&contextIgnoringTransport{}, 3, time.Minute)
+	if _, err := transport.RoundTrip(req); err != context.Canceled {
+		t.Errorf("retryTransport.RoundTrip() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+// contextIgnoringTransport responds with a server error without checking the context of the request
+type contextIgnoringTransport struct{}
+
+func (contextIgnoringTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody, Request: req}, nil
+}
+
+func Test_fetchWorkflowBillMap_retry(t *testing.T) {
+	var bodies []string
+	server := newSequenceServer(t, []response{
+		{status: http.StatusInternalServerError},
+		{status: http.StatusOK, body: `{"billable": {"UBUNTU": {"total_ms": 60000}}}`},
+	}, &bodies)
+
+	transport := newRetryTransport(http.DefaultTransport, 3, time.Minute)
+	transport.sleep = func(context.Context, time.Duration) error { return nil }
+	client := github.NewClient(&http.Client{Transport: transport})
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	got, err := fetchWorkflowBillMap(context.Background(), client, "owner", "repo", 1)
+	if err != nil {
+		t.Fatalf("fetchWorkflowBillMap() error = %v", err)
+	}
+	if minutes := getMinutesForEnv(got, "UBUNTU"); minutes != 1 || len(bodies) != 2 {
+		t.Errorf("fetchWorkflowBillMap() = %d minutes after %d requests, want 1 minute after 2 requests", minutes, len(bodies))
+	}
+}
+
+func Test_retryTransport_exhaustedRateLimit(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10)
+	var bodies []string
+	server := newSequenceServer(t, []response{
+		{status: http.StatusOK, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}, body: `{"billable": {}}`},
+		{status: http.StatusOK, headers: map[string]string{"X-RateLimit-Remaining": "4999"}, body: `{"billable": {"UBUNTU": {"total_ms": 60000}}}`},
+	}, &bodies)
+
+	var waits []time.Duration
+	transport := newRetryTransport(http.DefaultTransport, 3, time.Minute)
+	transport.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	client := github.NewClient(&http.Client{Transport: transport})
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	if _, err := fetchWorkflowBillMap(context.Background(), client, "owner", "repo", 1); err != nil {
+		t.Fatalf("fetchWorkflowBillMap() error = %v", err)
+	}
+	// go-github rejects the request by the cached rate limit unless the transport waits for the reset
+	got, err := fetchWorkflowBillMap(context.Background(), client, "owner", "repo", 2)
+	if err != nil {
+		t.Fatalf("fetchWorkflowBillMap() error = %v", err)
+	}
+	if minutes := getMinutesForEnv(got, "UBUNTU"); minutes != 1 || len(bodies) != 2 {
+		t.Errorf("fetchWorkflowBillMap() = %d minutes after %d requests, want 1 minute after 2 requests", minutes, len(bodies))
+	}
+	if len(waits) != 1 || waits[0] < 29*time.Second || waits[0] > 31*time.Second {
+		t.Errorf("retryTransport waited %v, want about 31s before the second request", waits)
+	}
+}
@@ -0,0 +1,162 @@
+package bills
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+)
+
+const largerRunnersTableHeader = "| Workflow | Runner | Jobs | Minutes | Cost (USD) |\n| --- | --- | --- | --- | --- |\n"
+
+// coresPattern matches the number of cores in a runner label (e.g. ubuntu-22.04-16core, linux-4-cores, windows_8_core)
+var coresPattern = regexp.MustCompile(`(\d+)[-_]?cores?\b`)
+
+// LargerRunnerBillableTime represents the total billable time of the jobs of a workflow run on a larger runner SKU
+type LargerRunnerBillableTime struct {
+	WorkflowID   int64  // ID of the workflow the jobs belong to
+	WorkflowName string // Name of the workflow the jobs belong to
+	SKU          string // Larger runner SKU (e.g. linux-4-core, windows-8-core)
+	Jobs         int    // Number of jobs run on the SKU
+	Minutes      int64  // Total billable time (in minutes). Each job is rounded up to the minute as GitHub does
+	TotalMS      int64  // Total billable time (in milliseconds)
+}
+
+// generateLargerRunnerBillableTimes generates the billable time of the jobs run on larger runners, aggregated by workflow and SKU.
+// The duration of each job is calculated from its start and completion times, as the usage API does not include larger runners.
+// skus maps runner labels or runner group names to SKUs, and takes precedence over the SKU inferred from the labels.
+func generateLargerRunnerBillableTimes(runJobs []workflowRunJobs, skus map[string]string) []LargerRunnerBillableTime {
+	type runnerKey struct {
+		workflowID int64
+		sku        string
+	}
+	aggregated := make(map[runnerKey]*LargerRunnerBillableTime)
+
+	for _, rj := range runJobs {
+		for _, job := range rj.jobs {
+			if job.StartedAt == nil || job.CompletedAt == nil {
+				continue
+			}
+			sku, ok := classifyRunner(job, skus)
+			if !ok {
+				continue
+			}
+			durationMS := job.GetCompletedAt().Sub(job.GetStartedAt().Time).Milliseconds()
+			if durationMS <= 0 {
+				continue
+			}
+
+			key := runnerKey{workflowID: rj.run.GetWorkflowID(), sku: sku}
+			lrbt, ok := aggregated[key]
+			if !ok {
+				lrbt = &LargerRunnerBillableTime{WorkflowID: rj.run.GetWorkflowID(), WorkflowName: rj.run.GetName(), SKU: sku}
+				aggregated[key] = lrbt
+			}
+			lrbt.Jobs++
+			lrbt.Minutes += (durationMS + 59999) / 60000 // round up to the minute
+			lrbt.TotalMS += durationMS
+		}
+	}
+
+	lrbts := make([]LargerRunnerBillableTime, 0, len(aggregated))
+	for _, lrbt := range aggregated {
+		lrbts = append(lrbts, *lrbt)
+	}
+	sort.Slice(lrbts, func(i, j int) bool {
+		if lrbts[i].WorkflowName != lrbts[j].WorkflowName {
+			return lrbts[i].WorkflowName < lrbts[j].WorkflowName
+		}
+		if lrbts[i].WorkflowID != lrbts[j].WorkflowID {
+			return lrbts[i].WorkflowID < lrbts[j].WorkflowID
+		}
+		return lrbts[i].SKU < lrbts[j].SKU
+	})
+
+	return lrbts
+}
+
+// classifyRunner returns the larger runner SKU the job ran on.
+// It returns false for jobs run on standard GitHub-hosted runners or self-hosted runners.
+func classifyRunner(job *github.WorkflowJob, skus map[string]string) (string, bool) {
+	for _, label := range append([]string{job.GetRunnerGroupName()}, job.Labels...) {
+		if sku, ok := skus[label]; ok {
+			return sku, true
+		}
+	}
+
+	for _, label := range job.Labels {
+		if strings.EqualFold(label, "self-hosted") {
+			return "", false
+		}
+	}
+
+	for _, label := range job.Labels {
+		if sku, ok := inferRunnerSKU(label); ok {
+			return sku, true
+		}
+	}
+
+	return "", false
+}
+
+// inferRunnerSKU infers the larger runner SKU from a runner label.
+// A label is considered a larger runner if it contains the number of cores (e.g. ubuntu-22.04-16core)
+// or is a macOS larger runner label (e.g. macos-14-large, macos-14-xlarge).
+func inferRunnerSKU(label string) (string, bool) {
+	label = strings.ToLower(label)
+
+	if strings.HasPrefix(label, "macos") {
+		switch {
+		case strings.HasSuffix(label, "-xlarge"):
+			return "macos-6-core-arm", true
+		case strings.HasSuffix(label, "-large"):
+			return "macos-12-core", true
+		}
+	}
+
+	matches := coresPattern.FindStringSubmatch(label)
+	if matches == nil {
+		return "", false
+	}
+
+	env := "linux"
+	switch {
+	case strings.Contains(label, "windows"):
+		env = "windows"
+	case strings.Contains(label, "macos"):
+		env = "macos"
+	}
+
+	sku := fmt.Sprintf("%s-%s-core", env, matches[1])
+	if strings.Contains(label, "gpu") {
+		sku += "-gpu"
+	}
+	return sku, true
+}
+
+// generateLargerRunnersMarkdown generates a markdown section with a table of the billable time on larger runners
+func generateLargerRunnersMarkdown(heading string, lrbts []LargerRunnerBillableTime, pricing Pricing) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n%s Larger runners\n\n", heading))
+	sb.WriteString(largerRunnersTableHeader)
+
+	var jobs int
+	var minutes int64
+	var cost float64
+	for _, lrbt := range lrbts {
+		price, ok := pricing.LargerRunners[lrbt.SKU]
+		costText := "-"
+		if ok {
+			costText = formatCost(float64(lrbt.Minutes) * price)
+			cost += float64(lrbt.Minutes) * price
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %s |\n", escapeMarkdownCell(lrbt.WorkflowName), escapeMarkdownCell(lrbt.SKU), lrbt.Jobs, lrbt.Minutes, costText))
+		jobs += lrbt.Jobs
+		minutes += lrbt.Minutes
+	}
+	sb.WriteString(fmt.Sprintf("| **Total** | | **%d** | **%d** | **%s** |\n", jobs, minutes, formatCost(cost)))
+
+	return sb.String()
+}
@@ -0,0 +1,152 @@
+package bills
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+func Test_inferRunnerSKU(t *testing.T) {
+	tests := []struct {
+		label  string
+		want   string
+		wantOK bool
+	}{
+		{label: "ubuntu-latest", want: "", wantOK: false},
+		{label: "windows-2022", want: "", wantOK: false},
+		{label: "macos-14", want: "", wantOK: false},
+		{label: "ubuntu-22.04-16core", want: "linux-16-core", wantOK: true},
+		{label: "linux-4-cores", want: "linux-4-core", wantOK: true},
+		{label: "Windows_8_Core", want: "windows-8-core", wantOK: true},
+		{label: "gpu-runner-4core", want: "linux-4-core-gpu", wantOK: true},
+		{label: "macos-14-large", want: "macos-12-core", wantOK: true},
+		{label: "macos-latest-xlarge", want: "macos-6-core-arm", wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			got, ok := inferRunnerSKU(tt.label)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("inferRunnerSKU() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_classifyRunner(t *testing.T) {
+	skus := map[string]string{
+		"big-runner":       "linux-8-core",
+		"Windows Builders": "windows-16-core",
+	}
+	tests := []struct {
+		name   string
+		job    *github.WorkflowJob
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "standard",
+			job:    &github.WorkflowJob{Labels: []string{"ubuntu-latest"}, RunnerGroupName: github.String("GitHub Actions")},
+			want:   "",
+			wantOK: false,
+		},
+		{
+			name:   "label mapping",
+			job:    &github.WorkflowJob{Labels: []string{"big-runner"}},
+			want:   "linux-8-core",
+			wantOK: true,
+		},
+		{
+			name:   "runner group mapping",
+			job:    &github.WorkflowJob{Labels: []string{"windows-runner"}, RunnerGroupName: github.String("Windows Builders")},
+			want:   "windows-16-core",
+			wantOK: true,
+		},
+		{
+			name:   "inferred",
+			job:    &github.WorkflowJob{Labels: []string{"ubuntu-22.04-4core"}},
+			want:   "linux-4-core",
+			wantOK: true,
+		},
+		{
+			name:   "self-hosted",
+			job:    &github.WorkflowJob{Labels: []string{"self-hosted", "linux-64-core"}},
+			want:   "",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := classifyRunner(tt.job, skus)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("classifyRunner() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_generateLargerRunnerBillableTimes(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	job := func(label string, duration time.Duration) *github.WorkflowJob {
+		return &github.WorkflowJob{
+			Labels:      []string{label},
+			StartedAt:   &github.Timestamp{Time: start},
+			CompletedAt: &github.Timestamp{Time: start.Add(duration)},
+		}
+	}
+	runJobs := []workflowRunJobs{
+		{
+			run: &github.WorkflowRun{WorkflowID: github.Int64(1), Name: github.String("CI")},
+			jobs: []*github.WorkflowJob{
+				job("ubuntu-latest", 10*time.Minute),
+				job("ubuntu-22.04-4core", 90*time.Second),
+				job("windows-8core", 5*time.Minute),
+				{Labels: []string{"ubuntu-22.04-4core"}, StartedAt: &github.Timestamp{Time: start}}, // in progress
+			},
+		},
+		{
+			run: &github.WorkflowRun{WorkflowID: github.Int64(1), Name: github.String("CI")},
+			jobs: []*github.WorkflowJob{
+				job("ubuntu-22.04-4core", 60*time.Second),
+			},
+		},
+		{
+			run: &github.WorkflowRun{WorkflowID: github.Int64(2), Name: github.String("Build")},
+			jobs: []*github.WorkflowJob{
+				job("build-runner", 3*time.Minute),
+			},
+		},
+	}
+	want := []LargerRunnerBillableTime{
+		{WorkflowID: 2, WorkflowName: "Build", SKU: "linux-16-core", Jobs: 1, Minutes: 3, TotalMS: 180000},
+		{WorkflowID: 1, WorkflowName: "CI", SKU: "linux-4-core", Jobs: 2, Minutes: 3, TotalMS: 150000},
+		{WorkflowID: 1, WorkflowName: "CI", SKU: "windows-8-core", Jobs: 1, Minutes: 5, TotalMS: 300000},
+	}
+
+	got := generateLargerRunnerBillableTimes(runJobs, map[string]string{"build-runner": "linux-16-core"})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("generateLargerRunnerBillableTimes() = %v, want %v", got, want)
+	}
+}
+
+func Test_generateLargerRunnersMarkdown(t *testing.T) {
+	lrbts := []LargerRunnerBillableTime{
+		{WorkflowID: 1, WorkflowName: "CI", SKU: "linux-4-core", Jobs: 2, Minutes: 3, TotalMS: 150000},
+		{WorkflowID: 1, WorkflowName: "CI", SKU: "custom-sku", Jobs: 1, Minutes: 5, TotalMS: 300000},
+		{WorkflowID: 2, WorkflowName: "Build | Release", SKU: "gpu|large\nrunner", Jobs: 1, Minutes: 1, TotalMS: 60000},
+	}
+	want := `
+### Larger runners
+
+| Workflow | Runner | Jobs | Minutes | Cost (USD) |
+| --- | --- | --- | --- | --- |
+| CI | linux-4-core | 2 | 3 | $0.05 |
+| CI | custom-sku | 1 | 5 | - |
+| Build \| Release | gpu\|large runner | 1 | 1 | - |
+| **Total** | | **4** | **9** | **$0.05** |
+`
+	if got := generateLargerRunnersMarkdown("###", lrbts, DefaultPricing); got != want {
+		t.Errorf("generateLargerRunnersMarkdown() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package bills
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultServeInterval is the default interval of refreshing the metrics served by Serve
+	DefaultServeInterval = 10 * time.Minute
+	// minServeInterval is the minimum interval of refreshing the metrics, keeping the requests to the GitHub API low
+	minServeInterval = time.Minute
+	// serveShutdownTimeout is the time to wait for the scrapes in progress on shutdown
+	serveShutdownTimeout = 5 * time.Second
+)
+
+// metricsServer serves the OpenMetrics exposition of the latest report, refreshed on an interval.
+// Scrapes are served from the cached exposition, so they never send requests to the GitHub API.
+type metricsServer struct {
+	refresh  func(ctx context.Context) (Report, error) // Creates a new report
+	interval time.Duration                             // Interval of refreshing the report
+
+	mu      sync.RWMutex
+	metrics string // Exposition of the latest report (empty until the first refresh succeeds)
+}
+
+// run refreshes the metrics immediately and then on every interval until the context is done
+func (s *metricsServer) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.update(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update refreshes the report and caches its exposition.
+// If the refresh fails, the previous exposition is kept so the scrapes keep the latest known values.
+func (s *metricsServer) update(ctx context.Context) {
+	report, err := s.refresh(ctx)
+	if err != nil {
+		log.Printf("failed to refresh the metrics: %v", err)
+		return
+	}
+	metrics, err := report.generateOpenMetricsReport()
+	if err != nil {
+		log.Printf("failed to refresh the metrics: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics = metrics
+}
+
+// ServeHTTP serves the cached exposition on /metrics, or 503 Service Unavailable until the first refresh succeeds
+func (s *metricsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/metrics" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.RLock()
+	metrics := s.metrics
+	s.mu.RUnlock()
+	if metrics == "" {
+		http.Error(w, "metrics are not available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", openMetricsContentType)
+	_, _ = io.WriteString(w, metrics)
+}
+
+// Serve exposes the billable time of the workflows of opts.Repository or opts.Organization in the OpenMetrics format on /metrics of addr.
+// The report is refreshed every interval, and the scrapes are served from the latest report.
+// It returns when ctx is done or the server fails.
+func Serve(ctx context.Context, opts Options, addr string, interval time.Duration) error {
+	if interval < minServeInterval {
+		return fmt.Errorf("interval must be at least %s: %s", minServeInterval, interval)
+	}
+	if err := opts.Filter.validate(); err != nil {
+		return err
+	}
+
+	pricing, err := resolvePricing(opts)
+	if err != nil {
+		return err
+	}
+	client, err := createGitHubClient(opts)
+	if err != nil {
+		return err
+	}
+
+	s := &metricsServer{
+		interval: interval,
+		refresh: func(ctx context.Context) (Report, error) {
+			report, err := createReport(ctx, client, opts)
+			if err != nil {
+				return Report{}, err
+			}
+			report.Pricing = pricing
+			report.GeneratedAt = time.Now().UTC()
+			return report, nil
+		},
+	}
+	server := &http.Server{Addr: addr, Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	go s.run(ctx)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	log.Printf("serving metrics on %s/metrics, refreshed every %s", addr, interval)
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to shut down the metrics server: %w", err)
+		}
+		return nil
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve metrics on %s: %w", addr, err)
+	}
+}
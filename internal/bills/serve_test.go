@@ -0,0 +1,79 @@
+package bills
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_metricsServer(t *testing.T) {
+	refreshes := 0
+	var refreshErr error
+	s := &metricsServer{
+		interval: time.Hour,
+		refresh: func(context.Context) (Report, error) {
+			refreshes++
+			if refreshErr != nil {
+				return Report{}, refreshErr
+			}
+			return Report{
+				Pricing:      DefaultPricing,
+				GeneratedAt:  time.Unix(int64(refreshes), 0),
+				Repositories: []RepositoryBillableTime{{Repository: "owner/repo", Workflows: WorkflowBillableTimes{{ID: 1, Name: "CI"}}}},
+			}, nil
+		},
+	}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	scrape := func(path string) (int, string, string) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("failed to scrape %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+	}
+
+	if status, _, _ := scrape("/metrics"); status != http.StatusServiceUnavailable {
+		t.Errorf("status before the first refresh = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+
+	s.update(context.Background())
+	for i := 0; i < 3; i++ {
+		status, contentType, body := scrape("/metrics")
+		if status != http.StatusOK || contentType != openMetricsContentType {
+			t.Errorf("status = %d, Content-Type = %s, want %d, %s", status, contentType, http.StatusOK, openMetricsContentType)
+		}
+		if !strings.Contains(body, "actbills_report_generated_timestamp_seconds 1\n") {
+			t.Errorf("body = %v, want the metrics of the first refresh", body)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1 as the scrapes are served from the cache", refreshes)
+	}
+
+	// the metrics of the last successful refresh are kept if a refresh fails
+	refreshErr = errors.New("server error")
+	s.update(context.Background())
+	if _, _, body := scrape("/metrics"); !strings.Contains(body, "actbills_report_generated_timestamp_seconds 1\n") {
+		t.Errorf("body after a failed refresh = %v, want the metrics of the first refresh", body)
+	}
+
+	if status, _, _ := scrape("/"); status != http.StatusNotFound {
+		t.Errorf("status of / = %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestServe_interval(t *testing.T) {
+	if err := Serve(context.Background(), Options{}, "127.0.0.1:0", time.Second); err == nil {
+		t.Error("Serve() error = nil, want an error for an interval shorter than a minute")
+	}
+}
@@ -0,0 +1,187 @@
+package bills
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// snapshotStoreVersion is the version of the snapshot file. It is incremented on breaking changes.
+const snapshotStoreVersion = 1
+
+// DefaultSnapshotKeep is the default number of snapshots kept in the snapshot file
+const DefaultSnapshotKeep = 90
+
+// Snapshot represents the billable times of the workflows at the time a report was generated
+type Snapshot struct {
+	GeneratedAt  time.Time            `json:"generated_at"` // Time the report was generated
+	Repositories []SnapshotRepository `json:"repositories"` // Billable times for each repository
+}
+
+// SnapshotRepository represents the billable times of the workflows in a repository in a snapshot
+type SnapshotRepository struct {
+	Repository string             `json:"repository"` // Repository name in owner/repo format
+	Workflows  []SnapshotWorkflow `json:"workflows"`  // Billable times for each workflow
+}
+
+// SnapshotWorkflow represents the billable time of a workflow in a snapshot
+type SnapshotWorkflow struct {
+	ID        int64  `json:"id"`         // Workflow ID
+	Name      string `json:"name"`       // Workflow display name
+	Path      string `json:"path"`       // Workflow file path
+	Ubuntu    int64  `json:"ubuntu"`     // Billable time for the Ubuntu environment (in minutes)
+	Windows   int64  `json:"windows"`    // Billable time for the Windows environment (in minutes)
+	Macos     int64  `json:"macos"`      // Billable time for the Mac environment (in minutes)
+	UbuntuMS  int64  `json:"ubuntu_ms"`  // Billable time for the Ubuntu environment (in milliseconds)
+	WindowsMS int64  `json:"windows_ms"` // Billable time for the Windows environment (in milliseconds)
+	MacosMS   int64  `json:"macos_ms"`   // Billable time for the Mac environment (in milliseconds)
+}
+
+// snapshotStore represents the snapshot file, with the snapshots in chronological order
+type snapshotStore struct {
+	Version   int        `json:"version"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// loadSnapshots loads the snapshots from the JSON file specified by the filePath.
+// If the file does not exist, an empty store is returned so the first run starts the history.
+func loadSnapshots(filePath string) (snapshotStore, error) {
+	store := snapshotStore{Version: snapshotStoreVersion}
+
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return snapshotStore{}, fmt.Errorf("failed to read snapshot file %s: %w", filePath, err)
+	}
+	if err := json.Unmarshal(data, &store); err != nil {
+		return snapshotStore{}, fmt.Errorf("failed to parse snapshot file %s: %w", filePath, err)
+	}
+	if store.Version != snapshotStoreVersion {
+		return snapshotStore{}, fmt.Errorf("unsupported snapshot file version %d in %s", store.Version, filePath)
+	}
+
+	return store, nil
+}
+
+// save writes the store to the JSON file specified by the filePath
+func (s snapshotStore) save(filePath string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot file %s: %w", filePath, err)
+	}
+	return writeToFile(filePath, string(data)+"\n")
+}
+
+// latest returns the latest snapshot generated since the specified time.
+// It returns nil if there is none, e.g. on the first run of a billing cycle.
+func (s snapshotStore) latest(since time.Time) *Snapshot {
+	var latest *Snapshot
+	for i := range s.Snapshots {
+		snapshot := &s.Snapshots[i]
+		if snapshot.GeneratedAt.Before(since) {
+			continue
+		}
+		if latest == nil || snapshot.GeneratedAt.After(latest.GeneratedAt) {
+			latest = snapshot
+		}
+	}
+	return latest
+}
+
+// oldest returns the oldest snapshot generated since the specified time.
+// It returns nil if there is none.
+func (s snapshotStore) oldest(since time.Time) *Snapshot {
+	var oldest *Snapshot
+	for i := range s.Snapshots {
+		snapshot := &s.Snapshots[i]
+		if snapshot.GeneratedAt.Before(since) {
+			continue
+		}
+		if oldest == nil || snapshot.GeneratedAt.Before(oldest.GeneratedAt) {
+			oldest = snapshot
+		}
+	}
+	return oldest
+}
+
+// append appends the snapshot to the store and drops the oldest snapshots to keep at most keep snapshots.
+// If keep is zero or negative, all snapshots are kept.
+func (s snapshotStore) append(snapshot Snapshot, keep int) snapshotStore {
+	snapshots := append(append([]Snapshot{}, s.Snapshots...), snapshot)
+	if keep > 0 && len(snapshots) > keep {
+		snapshots = snapshots[len(snapshots)-keep:]
+	}
+	return snapshotStore{Version: snapshotStoreVersion, Snapshots: snapshots}
+}
+
+// newSnapshot creates a snapshot of the billable times of the workflows in the report
+func newSnapshot(r Report) Snapshot {
+	snapshot := Snapshot{GeneratedAt: r.GeneratedAt, Repositories: []SnapshotRepository{}}
+	for _, rbt := range r.Repositories {
+		repository := SnapshotRepository{Repository: rbt.Repository, Workflows: []SnapshotWorkflow{}}
+		for _, wbt := range rbt.Workflows.sortWorkflows() {
+			repository.Workflows = append(repository.Workflows, SnapshotWorkflow{
+				ID:        wbt.ID,
+				Name:      wbt.Name,
+				Path:      wbt.Path,
+				Ubuntu:    wbt.Ubuntu,
+				Windows:   wbt.Windows,
+				Macos:     wbt.Macos,
+				UbuntuMS:  wbt.UbuntuMS,
+				WindowsMS: wbt.WindowsMS,
+				MacosMS:   wbt.MacosMS,
+			})
+		}
+		snapshot.Repositories = append(snapshot.Repositories, repository)
+	}
+	return snapshot
+}
+
+// workflows returns the billable times of the workflows of the repository in the snapshot keyed by workflow ID.
+// An empty map is returned if the repository is not in the snapshot, so all of its workflows are treated as new.
+func (s Snapshot) workflows(repository string) map[int64]BillableTime {
+	workflows := make(map[int64]BillableTime)
+	for _, sr := range s.Repositories {
+		if sr.Repository != repository {
+			continue
+		}
+		for _, sw := range sr.Workflows {
+			workflows[sw.ID] = sw.billableTime()
+		}
+	}
+	return workflows
+}
+
+// matchedTotals calculates the total billable time of the workflows found both in the report and in the snapshot,
+// matched by repository and workflow ID, as of the snapshot (before) and as of the report (after).
+// matched is false if none of the workflows of the report is in the snapshot.
+func (s Snapshot) matchedTotals(r Report) (before, after BillableTime, matched bool) {
+	for _, rbt := range r.Repositories {
+		previous := s.workflows(rbt.Repository)
+		for _, wbt := range rbt.Workflows {
+			if b, ok := previous[wbt.ID]; ok {
+				before = before.add(b)
+				after = after.add(wbt.BillableTime)
+				matched = true
+			}
+		}
+	}
+	return before, after, matched
+}
+
+// billableTime returns the billable time for each environment of the workflow in the snapshot
+func (sw SnapshotWorkflow) billableTime() BillableTime {
+	return BillableTime{
+		Ubuntu:    sw.Ubuntu,
+		Windows:   sw.Windows,
+		Macos:     sw.Macos,
+		UbuntuMS:  sw.UbuntuMS,
+		WindowsMS: sw.WindowsMS,
+		MacosMS:   sw.MacosMS,
+	}
+}
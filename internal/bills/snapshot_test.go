@@ -0,0 +1,154 @@
+package bills
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_loadSnapshots(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		filePath string
+		want     snapshotStore
+		wantErr  bool
+	}{
+		{
+			name:     "not found",
+			filePath: filepath.Join(tempDir, "notfound.json"),
+			want:     snapshotStore{Version: 1},
+			wantErr:  false,
+		},
+		{
+			name:     "valid",
+			filePath: writeFile("valid.json", `{"version": 1, "snapshots": [{"generated_at": "2024-05-02T00:00:00Z", "repositories": [{"repository": "owner/repo", "workflows": [{"id": 1, "name": "CI", "ubuntu": 10}]}]}]}`),
+			want: snapshotStore{
+				Version: 1,
+				Snapshots: []Snapshot{
+					{
+						GeneratedAt: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+						Repositories: []SnapshotRepository{
+							{Repository: "owner/repo", Workflows: []SnapshotWorkflow{{ID: 1, Name: "CI", Ubuntu: 10}}},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "unsupported version",
+			filePath: writeFile("version.json", `{"version": 2, "snapshots": []}`),
+			want:     snapshotStore{},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid json",
+			filePath: writeFile("invalid.json", `{"snapshots":`),
+			want:     snapshotStore{},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadSnapshots(tt.filePath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadSnapshots() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadSnapshots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_snapshotStore_latest(t *testing.T) {
+	store := snapshotStore{
+		Version: 1,
+		Snapshots: []Snapshot{
+			{GeneratedAt: time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+			{GeneratedAt: time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)},
+			{GeneratedAt: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	tests := []struct {
+		name  string
+		since time.Time
+		want  *Snapshot
+	}{
+		{
+			name:  "latest in the billing cycle",
+			since: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			want:  &store.Snapshots[1],
+		},
+		{
+			name:  "none in the billing cycle",
+			since: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := store.latest(tt.since); got != tt.want {
+				t.Errorf("snapshotStore.latest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_snapshotStore_appendAndSave(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "snapshots.json")
+	report := Report{
+		GeneratedAt: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		Repositories: []RepositoryBillableTime{
+			{
+				Repository: "owner/repo",
+				Workflows: WorkflowBillableTimes{
+					{ID: 2, Name: "Release", BillableTime: BillableTime{Windows: 3, WindowsMS: 150000}},
+					{ID: 1, Name: "CI", BillableTime: BillableTime{Ubuntu: 10, UbuntuMS: 600000}},
+				},
+			},
+		},
+	}
+
+	store := snapshotStore{Version: 1}
+	for i := 0; i < 3; i++ {
+		store = store.append(newSnapshot(report), 2)
+	}
+	if len(store.Snapshots) != 2 {
+		t.Errorf("snapshotStore.append() kept %d snapshots, want 2", len(store.Snapshots))
+	}
+	if err := store.save(filePath); err != nil {
+		t.Fatalf("snapshotStore.save() error = %v", err)
+	}
+
+	got, err := loadSnapshots(filePath)
+	if err != nil {
+		t.Fatalf("loadSnapshots() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, store) {
+		t.Errorf("loadSnapshots() = %v, want %v", got, store)
+	}
+
+	want := map[int64]BillableTime{
+		1: {Ubuntu: 10, UbuntuMS: 600000},
+		2: {Windows: 3, WindowsMS: 150000},
+	}
+	if workflows := got.Snapshots[1].workflows("owner/repo"); !reflect.DeepEqual(workflows, want) {
+		t.Errorf("Snapshot.workflows() = %v, want %v", workflows, want)
+	}
+	if workflows := got.Snapshots[1].workflows("owner/other"); len(workflows) != 0 || workflows == nil {
+		t.Errorf("Snapshot.workflows() = %v, want empty map", workflows)
+	}
+}
@@ -0,0 +1,114 @@
+package bills
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortKey represents the key the workflow tables are sorted by
+type SortKey string
+
+const (
+	SortByName    SortKey = "name"    // Workflow name, then file path
+	SortByMinutes SortKey = "minutes" // Total billable minutes across the environments
+	SortByCost    SortKey = "cost"    // Estimated cost in USD
+	SortByUbuntu  SortKey = "ubuntu"  // Billable minutes for the Ubuntu environment
+	SortByWindows SortKey = "windows" // Billable minutes for the Windows environment
+	SortByMacos   SortKey = "macos"   // Billable minutes for the Mac environment
+)
+
+// SortKeys is the list of supported sort keys
+var SortKeys = []SortKey{SortByName, SortByMinutes, SortByCost, SortByUbuntu, SortByWindows, SortByMacos}
+
+// SortOrder represents the order the workflow tables are sorted in
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// WorkflowSort represents how the workflows are sorted and limited in the workflow tables
+type WorkflowSort struct {
+	Key   SortKey   // Key to sort by (default name)
+	Order SortOrder // Order to sort in (default ascending by name, descending by the other keys)
+	Top   int       // Number of workflows listed, with the rest collapsed into an "Other" row (0 lists all workflows)
+}
+
+// normalize fills the defaults and returns an error if the sort is not supported
+func (s WorkflowSort) normalize() (WorkflowSort, error) {
+	if s.Key == "" {
+		s.Key = SortByName
+	}
+	if !containsSortKey(s.Key) {
+		return s, fmt.Errorf("unsupported sort key: %s", s.Key)
+	}
+	switch s.Order {
+	case "":
+		s.Order = SortDescending
+		if s.Key == SortByName {
+			s.Order = SortAscending
+		}
+	case SortAscending, SortDescending:
+	default:
+		return s, fmt.Errorf("unsupported sort order: %s", s.Order)
+	}
+	if s.Top < 0 {
+		return s, fmt.Errorf("top must not be negative: %d", s.Top)
+	}
+	return s, nil
+}
+
+// containsSortKey returns true if the key is one of SortKeys
+func containsSortKey(key SortKey) bool {
+	for _, k := range SortKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// sortBy returns a copy of the workflows sorted as specified.
+// Workflows with the same value are sorted by name, then by path.
+func (w WorkflowBillableTimes) sortBy(s WorkflowSort, pricing Pricing) WorkflowBillableTimes {
+	sorted := w.sortWorkflows()
+	if s.Key == SortByName || s.Key == "" {
+		if s.Order == SortDescending {
+			for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+				sorted[i], sorted[j] = sorted[j], sorted[i]
+			}
+		}
+		return sorted
+	}
+
+	value := func(b BillableTime) float64 {
+		switch s.Key {
+		case SortByMinutes:
+			return float64(b.Ubuntu + b.Windows + b.Macos)
+		case SortByCost:
+			return pricing.cost(b)
+		case SortByUbuntu:
+			return float64(b.Ubuntu)
+		case SortByWindows:
+			return float64(b.Windows)
+		default:
+			return float64(b.Macos)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if s.Order == SortAscending {
+			return value(sorted[i].BillableTime) < value(sorted[j].BillableTime)
+		}
+		return value(sorted[i].BillableTime) > value(sorted[j].BillableTime)
+	})
+	return sorted
+}
+
+// otherRowTitle returns the title of the row collapsing the workflows not listed in the table
+func otherRowTitle(workflows int) string {
+	if workflows == 1 {
+		return "Other (1 workflow)"
+	}
+	return fmt.Sprintf("Other (%d workflows)", workflows)
+}
@@ -0,0 +1,152 @@
+package bills
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWorkflowSort_normalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       WorkflowSort
+		want    WorkflowSort
+		wantErr bool
+	}{
+		{name: "default", s: WorkflowSort{}, want: WorkflowSort{Key: SortByName, Order: SortAscending}},
+		{name: "cost", s: WorkflowSort{Key: SortByCost, Top: 5}, want: WorkflowSort{Key: SortByCost, Order: SortDescending, Top: 5}},
+		{name: "ascending", s: WorkflowSort{Key: SortByMacos, Order: SortAscending}, want: WorkflowSort{Key: SortByMacos, Order: SortAscending}},
+		{name: "unsupported key", s: WorkflowSort{Key: "duration"}, wantErr: true},
+		{name: "unsupported order", s: WorkflowSort{Order: "up"}, wantErr: true},
+		{name: "negative top", s: WorkflowSort{Top: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WorkflowSort.normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("WorkflowSort.normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowBillableTimes_sortBy(t *testing.T) {
+	w := WorkflowBillableTimes{
+		{ID: 1, Name: "build", BillableTime: BillableTime{Ubuntu: 30, Windows: 5}},
+		{ID: 2, Name: "deploy", BillableTime: BillableTime{Ubuntu: 10}},
+		{ID: 3, Name: "test", BillableTime: BillableTime{Macos: 4}},
+		{ID: 4, Name: "lint", BillableTime: BillableTime{Ubuntu: 10}},
+	}
+	tests := []struct {
+		name string
+		s    WorkflowSort
+		want []int64
+	}{
+		{name: "name", s: WorkflowSort{Key: SortByName, Order: SortAscending}, want: []int64{1, 2, 4, 3}},
+		{name: "name descending", s: WorkflowSort{Key: SortByName, Order: SortDescending}, want: []int64{3, 4, 2, 1}},
+		{name: "minutes", s: WorkflowSort{Key: SortByMinutes, Order: SortDescending}, want: []int64{1, 2, 4, 3}},
+		{name: "cost", s: WorkflowSort{Key: SortByCost, Order: SortDescending}, want: []int64{1, 3, 2, 4}},
+		{name: "cost ascending", s: WorkflowSort{Key: SortByCost, Order: SortAscending}, want: []int64{2, 4, 1, 3}},
+		{name: "ubuntu", s: WorkflowSort{Key: SortByUbuntu, Order: SortDescending}, want: []int64{1, 2, 4, 3}},
+		{name: "windows", s: WorkflowSort{Key: SortByWindows, Order: SortDescending}, want: []int64{1, 2, 4, 3}},
+		{name: "macos", s: WorkflowSort{Key: SortByMacos, Order: SortDescending}, want: []int64{3, 1, 2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			for _, wbt := range w.sortBy(tt.s, DefaultPricing) {
+				got = append(got, wbt.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WorkflowBillableTimes.sortBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowBillableTimes_generateMarkdownTable_top(t *testing.T) {
+	w := WorkflowBillableTimes{
+		{ID: 1, Name: "build", BillableTime: BillableTime{Ubuntu: 30, Windows: 5}},
+		{ID: 2, Name: "deploy", BillableTime: BillableTime{Ubuntu: 10}},
+		{ID: 3, Name: "test", BillableTime: BillableTime{Macos: 4}},
+		{ID: 4, Name: "lint", BillableTime: BillableTime{Ubuntu: 10}},
+	}
+	tests := []struct {
+		name     string
+		previous map[int64]BillableTime
+		s        WorkflowSort
+		want     string
+	}{
+		{
+			name: "top 2 by cost",
+			s:    WorkflowSort{Key: SortByCost, Order: SortDescending, Top: 2},
+			want: `| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| build | 30 | 5 | 0 | 40 | $0.32 |
+| test | 0 | 0 | 4 | 40 | $0.32 |
+| Other (2 workflows) | 20 | 0 | 0 | 20 | $0.16 |
+| **Total** | **50** | **5** | **4** | **100** | **$0.80** |
+`,
+		},
+		{
+			name:     "top 3 with previous",
+			previous: map[int64]BillableTime{1: {Ubuntu: 20}, 2: {Ubuntu: 10}, 4: {Ubuntu: 4}},
+			s:        WorkflowSort{Key: SortByUbuntu, Order: SortDescending, Top: 3},
+			want: `| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) | Ubuntu (+/-) | Windows (+/-) | Macos (+/-) |
+| --- | --- | --- | --- | --- | --- | --- | --- | --- |
+| build | 30 | 5 | 0 | 40 | $0.32 | +10 | +5 | 0 |
+| deploy | 10 | 0 | 0 | 10 | $0.08 | 0 | 0 | 0 |
+| lint | 10 | 0 | 0 | 10 | $0.08 | +6 | 0 | 0 |
+| Other (1 workflow) | 0 | 0 | 4 | 40 | $0.32 | 0 | 0 | +4 |
+| **Total** | **50** | **5** | **4** | **100** | **$0.80** | **+16** | **+5** | **+4** |
+`,
+		},
+		{
+			name: "top not exceeded",
+			s:    WorkflowSort{Key: SortByName, Order: SortAscending, Top: 4},
+			want: `| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) |
+| --- | --- | --- | --- | --- | --- |
+| build | 30 | 5 | 0 | 40 | $0.32 |
+| deploy | 10 | 0 | 0 | 10 | $0.08 |
+| lint | 10 | 0 | 0 | 10 | $0.08 |
+| test | 0 | 0 | 4 | 40 | $0.32 |
+| **Total** | **50** | **5** | **4** | **100** | **$0.80** |
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.generateMarkdownTable(DefaultPricing, tt.previous, tt.s, "Total"); got != tt.want {
+				t.Errorf("WorkflowBillableTimes.generateMarkdownTable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReport_render_sorted(t *testing.T) {
+	r := Report{
+		Pricing: DefaultPricing,
+		Sort:    WorkflowSort{Key: SortByCost, Order: SortDescending, Top: 1},
+		Repositories: []RepositoryBillableTime{
+			{Repository: "owner/repo", Workflows: WorkflowBillableTimes{
+				{ID: 1, Name: "build", BillableTime: BillableTime{Ubuntu: 30}},
+				{ID: 2, Name: "test", BillableTime: BillableTime{Macos: 4}},
+			}},
+		},
+	}
+	want := `| test | 0 | 0 | 4 | 40 | $0.32 |
+| Other (1 workflow) | 30 | 0 | 0 | 30 | $0.24 |
+| **Total** | **30** | **0** | **4** | **70** | **$0.56** |
+`
+
+	got, err := r.render(FormatMarkdown, defaultTemplate)
+	if err != nil {
+		t.Fatalf("Report.render() error = %v", err)
+	}
+	if !strings.Contains(got, want) {
+		t.Errorf("Report.render() = %v, want to contain %v", got, want)
+	}
+}
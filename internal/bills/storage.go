@@ -0,0 +1,213 @@
+package bills
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+const (
+	// bytesPerGB is the number of bytes in a GB as GitHub bills the storage
+	bytesPerGB = 1 << 30
+	// storageMarkdownArtifacts is the number of the largest artifacts listed in the markdown report
+	storageMarkdownArtifacts = 10
+
+	storageWorkflowsTableHeader = "| Workflow | Artifacts | Size | Cost (USD/month) |\n| --- | --- | --- | --- |\n"
+	storageArtifactsTableHeader = "| Artifact | Workflow run | Size | Expires |\n| --- | --- | --- | --- |\n"
+)
+
+// RepositoryStorage represents the Actions storage used by a repository
+type RepositoryStorage struct {
+	Artifacts  []ArtifactStorage // Artifacts not expired, sorted by size in descending order
+	Workflows  []WorkflowStorage // Total size of the artifacts of each workflow, sorted by size in descending order
+	CacheBytes int64             // Total size of the active caches (in bytes)
+	Caches     int               // Number of the active caches
+}
+
+// ArtifactStorage represents an artifact and the workflow run producing it
+type ArtifactStorage struct {
+	ID           int64     // Artifact ID
+	Name         string    // Artifact name
+	SizeBytes    int64     // Size of the artifact (in bytes)
+	ExpiresAt    time.Time // Time the artifact expires
+	RunID        int64     // ID of the workflow run producing the artifact
+	WorkflowID   int64     // ID of the workflow of the run (0 if the run could not be found)
+	WorkflowName string    // Name of the workflow of the run (empty if the run could not be found)
+}
+
+// WorkflowStorage represents the total size of the artifacts produced by a workflow
+type WorkflowStorage struct {
+	WorkflowID   int64  // Workflow ID (0 for the artifacts of the runs that could not be found)
+	WorkflowName string // Workflow name
+	Artifacts    int    // Number of the artifacts
+	SizeBytes    int64  // Total size of the artifacts (in bytes)
+}
+
+// generateRepositoryStorage generates the storage used by the artifacts and the caches of the repository.
+// The workflow of each artifact is looked up from its workflow run, and named after the workflows if found in them.
+// The artifacts of the workflows not included are left out, while the artifacts of the runs not found are kept as their workflows are unknown.
+func generateRepositoryStorage(ctx context.Context, client *github.Client, pool workerPool, owner, repo string, workflows WorkflowBillableTimes, included workflowSet) (RepositoryStorage, error) {
+	all, err := fetchArtifacts(ctx, client, owner, repo)
+	if err != nil {
+		return RepositoryStorage{}, err
+	}
+	var artifacts []*github.Artifact
+	for _, artifact := range all {
+		if !artifact.GetExpired() {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+
+	var runIDs []int64
+	seen := make(map[int64]bool)
+	for _, artifact := range artifacts {
+		id := artifact.GetWorkflowRun().GetID()
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			runIDs = append(runIDs, id)
+		}
+	}
+	runs, err := runPool(ctx, pool, runIDs, func(ctx context.Context, runID int64) (*github.WorkflowRun, error) {
+		run, err := fetchWorkflowRun(ctx, client, owner, repo, runID)
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == http.StatusNotFound {
+			return nil, nil // the run has been deleted while the artifacts are kept
+		}
+		return run, err
+	})
+	if err != nil {
+		return RepositoryStorage{}, err
+	}
+	runsByID := make(map[int64]*github.WorkflowRun, len(runIDs))
+	for i, id := range runIDs {
+		runsByID[id] = runs[i]
+	}
+	workflowNames := make(map[int64]string, len(workflows))
+	for _, wbt := range workflows {
+		workflowNames[wbt.ID] = wbt.Name
+	}
+
+	var storage RepositoryStorage
+	byWorkflow := make(map[int64]*WorkflowStorage)
+	for _, artifact := range artifacts {
+		as := ArtifactStorage{
+			ID:        artifact.GetID(),
+			Name:      artifact.GetName(),
+			SizeBytes: artifact.GetSizeInBytes(),
+			ExpiresAt: artifact.GetExpiresAt().Time,
+			RunID:     artifact.GetWorkflowRun().GetID(),
+		}
+		if run := runsByID[as.RunID]; run != nil {
+			if !included.contains(run.GetWorkflowID()) {
+				continue
+			}
+			as.WorkflowID = run.GetWorkflowID()
+			as.WorkflowName = run.GetName()
+			if name, ok := workflowNames[as.WorkflowID]; ok {
+				as.WorkflowName = name
+			}
+		}
+		storage.Artifacts = append(storage.Artifacts, as)
+
+		ws, ok := byWorkflow[as.WorkflowID]
+		if !ok {
+			ws = &WorkflowStorage{WorkflowID: as.WorkflowID, WorkflowName: as.WorkflowName}
+			if ws.WorkflowName == "" {
+				ws.WorkflowName = "Unknown workflow"
+			}
+			byWorkflow[as.WorkflowID] = ws
+		}
+		ws.Artifacts++
+		ws.SizeBytes += as.SizeBytes
+	}
+	for _, ws := range byWorkflow {
+		storage.Workflows = append(storage.Workflows, *ws)
+	}
+	sort.Slice(storage.Artifacts, func(i, j int) bool {
+		a, b := storage.Artifacts[i], storage.Artifacts[j]
+		if a.SizeBytes != b.SizeBytes {
+			return a.SizeBytes > b.SizeBytes
+		}
+		return a.ID < b.ID
+	})
+	sort.Slice(storage.Workflows, func(i, j int) bool {
+		a, b := storage.Workflows[i], storage.Workflows[j]
+		if a.SizeBytes != b.SizeBytes {
+			return a.SizeBytes > b.SizeBytes
+		}
+		return a.WorkflowName < b.WorkflowName
+	})
+
+	usage, err := fetchCacheUsage(ctx, client, owner, repo)
+	if err != nil {
+		return RepositoryStorage{}, err
+	}
+	storage.CacheBytes = usage.ActiveCachesSizeInBytes
+	storage.Caches = usage.ActiveCachesCount
+
+	return storage, nil
+}
+
+// artifactBytes returns the total size of the artifacts
+func (s RepositoryStorage) artifactBytes() int64 {
+	var total int64
+	for _, ws := range s.Workflows {
+		total += ws.SizeBytes
+	}
+	return total
+}
+
+// generateStorageMarkdown generates a markdown section with the size of the artifacts by workflow, the largest artifacts and the cache usage
+func generateStorageMarkdown(heading string, storage RepositoryStorage, pricing Pricing) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n%s Storage\n\n", heading))
+
+	if len(storage.Workflows) > 0 {
+		sb.WriteString(storageWorkflowsTableHeader)
+		var artifacts int
+		for _, ws := range storage.Workflows {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s |\n", escapeMarkdownCell(ws.WorkflowName), ws.Artifacts, formatBytes(ws.SizeBytes), formatCost(pricing.storageCost(ws.SizeBytes))))
+			artifacts += ws.Artifacts
+		}
+		total := storage.artifactBytes()
+		sb.WriteString(fmt.Sprintf("| **Total** | **%d** | **%s** | **%s** |\n", artifacts, formatBytes(total), formatCost(pricing.storageCost(total))))
+
+		n := min(len(storage.Artifacts), storageMarkdownArtifacts)
+		sb.WriteString(fmt.Sprintf("\nLargest artifacts (%d of %d):\n\n", n, len(storage.Artifacts)))
+		sb.WriteString(storageArtifactsTableHeader)
+		for _, as := range storage.Artifacts[:n] {
+			run := fmt.Sprintf("run %d", as.RunID)
+			if as.WorkflowName != "" {
+				run = fmt.Sprintf("%s (run %d)", escapeMarkdownCell(as.WorkflowName), as.RunID)
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", escapeMarkdownCell(as.Name), run, formatBytes(as.SizeBytes), as.ExpiresAt.Format(time.DateOnly)))
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("No artifacts.\n\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("Actions cache: %s in %d caches\n", formatBytes(storage.CacheBytes), storage.Caches))
+	return sb.String()
+}
+
+// formatBytes formats the size in bytes with a binary unit (e.g. 512 B, 1.5 MB, 20.0 GB) as GitHub shows the storage
+func formatBytes(bytes int64) string {
+	if bytes < 1<<10 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	units := []string{"KB", "MB", "GB", "TB"}
+	size := float64(bytes) / (1 << 10)
+	unit := 0
+	for size >= 1<<10 && unit < len(units)-1 {
+		size /= 1 << 10
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
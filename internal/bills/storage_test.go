@@ -0,0 +1,226 @@
+package bills
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func Test_generateRepositoryStorage(t *testing.T) {
+	expiresAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	artifact := func(id int64, name string, size, runID int64, expired bool) *github.Artifact {
+		return &github.Artifact{
+			ID:          github.Int64(id),
+			Name:        github.String(name),
+			SizeInBytes: github.Int64(size),
+			Expired:     github.Bool(expired),
+			ExpiresAt:   &github.Timestamp{Time: expiresAt},
+			WorkflowRun: &github.ArtifactWorkflowRun{ID: github.Int64(runID)},
+		}
+	}
+	runs := map[int64]*github.WorkflowRun{
+		10: {ID: github.Int64(10), WorkflowID: github.Int64(1), Name: github.String("CI run name")},
+		11: {ID: github.Int64(11), WorkflowID: github.Int64(1)},
+		20: {ID: github.Int64(20), WorkflowID: github.Int64(2), Name: github.String("Release")},
+	}
+	newClient := func() *github.Client {
+		return github.NewClient(mock.NewMockedHTTPClient(
+			mock.WithRequestMatch(
+				mock.GetReposActionsArtifactsByOwnerByRepo,
+				github.ArtifactList{Artifacts: []*github.Artifact{
+					artifact(100, "coverage", 1<<20, 10, false),
+					artifact(101, "binaries", 3<<30, 20, false),
+					artifact(102, "logs", 1<<10, 11, false),
+					artifact(103, "old", 5<<30, 10, true),
+					artifact(104, "orphan", 512, 30, false),
+				}},
+			),
+			mock.WithRequestMatchHandler(
+				mock.GetReposActionsRunsByOwnerByRepoByRunId,
+				http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+					// /repos/{owner}/{repo}/actions/runs/{run_id}
+					id, _ := strconv.ParseInt(strings.Split(r.URL.Path, "/")[6], 10, 64)
+					run, ok := runs[id]
+					if !ok {
+						mock.WriteError(rw, http.StatusNotFound, "Not Found")
+						return
+					}
+					_, _ = rw.Write(mock.MustMarshal(run))
+				}),
+			),
+			mock.WithRequestMatch(
+				mock.GetReposActionsCacheUsageByOwnerByRepo,
+				github.ActionsCacheUsage{ActiveCachesSizeInBytes: 2 << 30, ActiveCachesCount: 3},
+			),
+		))
+	}
+	workflows := WorkflowBillableTimes{{ID: 1, Name: "CI"}}
+
+	tests := []struct {
+		name     string
+		included workflowSet
+		want     RepositoryStorage
+	}{
+		{
+			name: "basic",
+			want: RepositoryStorage{
+				Artifacts: []ArtifactStorage{
+					{ID: 101, Name: "binaries", SizeBytes: 3 << 30, ExpiresAt: expiresAt, RunID: 20, WorkflowID: 2, WorkflowName: "Release"},
+					{ID: 100, Name: "coverage", SizeBytes: 1 << 20, ExpiresAt: expiresAt, RunID: 10, WorkflowID: 1, WorkflowName: "CI"},
+					{ID: 102, Name: "logs", SizeBytes: 1 << 10, ExpiresAt: expiresAt, RunID: 11, WorkflowID: 1, WorkflowName: "CI"},
+					{ID: 104, Name: "orphan", SizeBytes: 512, ExpiresAt: expiresAt, RunID: 30},
+				},
+				Workflows: []WorkflowStorage{
+					{WorkflowID: 2, WorkflowName: "Release", Artifacts: 1, SizeBytes: 3 << 30},
+					{WorkflowID: 1, WorkflowName: "CI", Artifacts: 2, SizeBytes: 1<<20 + 1<<10},
+					{WorkflowID: 0, WorkflowName: "Unknown workflow", Artifacts: 1, SizeBytes: 512},
+				},
+				CacheBytes: 2 << 30,
+				Caches:     3,
+			},
+		},
+		{
+			name:     "excluded workflow",
+			included: workflowSet{1: true},
+			want: RepositoryStorage{
+				Artifacts: []ArtifactStorage{
+					{ID: 100, Name: "coverage", SizeBytes: 1 << 20, ExpiresAt: expiresAt, RunID: 10, WorkflowID: 1, WorkflowName: "CI"},
+					{ID: 102, Name: "logs", SizeBytes: 1 << 10, ExpiresAt: expiresAt, RunID: 11, WorkflowID: 1, WorkflowName: "CI"},
+					{ID: 104, Name: "orphan", SizeBytes: 512, ExpiresAt: expiresAt, RunID: 30},
+				},
+				Workflows: []WorkflowStorage{
+					{WorkflowID: 1, WorkflowName: "CI", Artifacts: 2, SizeBytes: 1<<20 + 1<<10},
+					{WorkflowID: 0, WorkflowName: "Unknown workflow", Artifacts: 1, SizeBytes: 512},
+				},
+				CacheBytes: 2 << 30,
+				Caches:     3,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generateRepositoryStorage(context.Background(), newClient(), newWorkerPool(2, false), "owner", "repo", workflows, tt.included)
+			if err != nil {
+				t.Fatalf("generateRepositoryStorage() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateRepositoryStorage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generateStorageMarkdown(t *testing.T) {
+	expiresAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		storage RepositoryStorage
+		want    string
+	}{
+		{
+			name: "basic",
+			storage: RepositoryStorage{
+				Artifacts: []ArtifactStorage{
+					{ID: 101, Name: "binaries", SizeBytes: 4 << 30, ExpiresAt: expiresAt, RunID: 20, WorkflowID: 2, WorkflowName: "Release"},
+					{ID: 104, Name: "orphan", SizeBytes: 512, ExpiresAt: expiresAt, RunID: 30},
+				},
+				Workflows: []WorkflowStorage{
+					{WorkflowID: 2, WorkflowName: "Release", Artifacts: 1, SizeBytes: 4 << 30},
+					{WorkflowID: 0, WorkflowName: "Unknown workflow", Artifacts: 1, SizeBytes: 512},
+				},
+				CacheBytes: 1536 << 20,
+				Caches:     3,
+			},
+			want: `
+## Storage
+
+| Workflow | Artifacts | Size | Cost (USD/month) |
+| --- | --- | --- | --- |
+| Release | 1 | 4.0 GB | $1.00 |
+| Unknown workflow | 1 | 512 B | $0.00 |
+| **Total** | **2** | **4.0 GB** | **$1.00** |
+
+Largest artifacts (2 of 2):
+
+| Artifact | Workflow run | Size | Expires |
+| --- | --- | --- | --- |
+| binaries | Release (run 20) | 4.0 GB | 2024-06-01 |
+| orphan | run 30 | 512 B | 2024-06-01 |
+
+Actions cache: 1.5 GB in 3 caches
+`,
+		},
+		{
+			name: "escaped names",
+			storage: RepositoryStorage{
+				Artifacts: []ArtifactStorage{
+					{ID: 101, Name: "report|html", SizeBytes: 1 << 20, ExpiresAt: expiresAt, RunID: 20, WorkflowID: 2, WorkflowName: "Build | Release"},
+				},
+				Workflows: []WorkflowStorage{
+					{WorkflowID: 2, WorkflowName: "Build | Release", Artifacts: 1, SizeBytes: 1 << 20},
+				},
+			},
+			want: `
+## Storage
+
+| Workflow | Artifacts | Size | Cost (USD/month) |
+| --- | --- | --- | --- |
+| Build \| Release | 1 | 1.0 MB | $0.00 |
+| **Total** | **1** | **1.0 MB** | **$0.00** |
+
+Largest artifacts (1 of 1):
+
+| Artifact | Workflow run | Size | Expires |
+| --- | --- | --- | --- |
+| report\|html | Build \| Release (run 20) | 1.0 MB | 2024-06-01 |
+
+Actions cache: 0 B in 0 caches
+`,
+		},
+		{
+			name:    "no artifacts",
+			storage: RepositoryStorage{},
+			want: `
+## Storage
+
+No artifacts.
+
+Actions cache: 0 B in 0 caches
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := generateStorageMarkdown("##", tt.storage, DefaultPricing); got != tt.want {
+				t.Errorf("generateStorageMarkdown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatBytes(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{bytes: 0, want: "0 B"},
+		{bytes: 1023, want: "1023 B"},
+		{bytes: 1536, want: "1.5 KB"},
+		{bytes: 20 << 30, want: "20.0 GB"},
+		{bytes: 2048 << 40, want: "2048.0 TB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatBytes(tt.bytes); got != tt.want {
+				t.Errorf("formatBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package bills
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultTemplateText is the built-in template of the markdown report
+//
+//go:embed templates/default.md.tmpl
+var defaultTemplateText string
+
+// templateFuncs are the functions available in the report templates in addition to the built-in ones
+var templateFuncs = template.FuncMap{
+	"cost":    formatCost,         // formats a cost in USD, e.g. {{ cost .Total.CostUSD }} renders $1.23
+	"minutes": formatMinutes,      // formats minutes without trailing zeros, e.g. {{ minutes .Total.Weighted }}
+	"bytes":   formatBytes,        // formats a size in bytes, e.g. {{ bytes .Storage.CacheBytes }} renders 1.5 GB
+	"escape":  escapeMarkdownCell, // escapes the pipes and line breaks of a markdown table cell
+}
+
+// defaultTemplate is the parsed built-in template of the markdown report
+var defaultTemplate = template.Must(template.New("default").Funcs(templateFuncs).Parse(defaultTemplateText))
+
+// TemplateData is the data passed to the template of the markdown report.
+// The fields hold the data of the report, and the methods render the sections of the built-in template,
+// so a custom template can reuse them, e.g. {{ range .Repositories }}{{ .WorkflowTable }}{{ end }}.
+type TemplateData struct {
+	Title           string               // Title of the built-in template
+	Note            string               // Note at the end of the built-in template
+	GeneratedAt     time.Time            // Time the report was generated (UTC)
+	PreviousAt      *time.Time           // Time the previous snapshot was taken (nil without a previous snapshot)
+	Organization    string               // Organization name (empty for a single repository report)
+	MultiRepository bool                 // Whether the report covers several repositories, of an organization or listed
+	Repositories    []TemplateRepository // Repositories in the report
+	Total           TemplateBillableTime // Total billable time across all repositories
+	Pricing         Pricing              // Pricing used to estimate the weighted minutes and the cost
+	Forecast        *Forecast            // Billable time projected to the end of the billing cycle (nil if not requested)
+	Failures        []FetchFailure       // Workflows and repositories that could not be fetched (only in tolerant mode)
+	Billing         *BillingSummary      // Actions billing summary of the owner (nil if not requested)
+	Filtered        bool                 // Whether the workflows are filtered by --include-* and --exclude-*
+	Excluded        int                  // Number of the workflows excluded by the filters across all repositories
+
+	report Report
+}
+
+// TemplateRepository is the data of a repository passed to the report template
+type TemplateRepository struct {
+	Name          string                     // Repository name in owner/repo format
+	Workflows     []TemplateWorkflow         // All workflows sorted as the workflow table (by name, then by path by default)
+	Total         TemplateBillableTime       // Total billable time of the workflows
+	Jobs          []JobBillableTime          // Top jobs by billable time in this billing cycle (only with --top-jobs)
+	LargerRunners []LargerRunnerBillableTime // Billable time on larger runners in this billing cycle (only with --larger-runners)
+	Storage       *RepositoryStorage         // Storage used by the artifacts and the caches (only with --storage)
+	Excluded      int                        // Number of the workflows excluded by the filters
+
+	rbt      RepositoryBillableTime
+	pricing  Pricing
+	previous map[int64]BillableTime
+	sort     WorkflowSort
+	filtered bool
+}
+
+// TemplateWorkflow is the data of a workflow passed to the report template
+type TemplateWorkflow struct {
+	ID          int64  // Workflow ID
+	Name        string // Workflow name
+	DisplayName string // Workflow name with the path appended if another workflow shares the name
+	Path        string // Workflow file path
+	State       string // Workflow state (e.g. active, disabled_manually)
+	TemplateBillableTime
+}
+
+// TemplateBillableTime is the billable time passed to the report template
+type TemplateBillableTime struct {
+	Ubuntu   int64         // Billable time for the Ubuntu environment (in minutes)
+	Windows  int64         // Billable time for the Windows environment (in minutes)
+	Macos    int64         // Billable time for the Mac environment (in minutes)
+	Weighted float64       // Billable minutes with the minute multiplier of each environment applied
+	CostUSD  float64       // Estimated cost (in USD)
+	Change   *BillableTime // Change since the previous snapshot (nil without a previous snapshot)
+}
+
+// loadTemplate loads the report template from the file specified by the filePath.
+// If the filePath is empty, the built-in template is returned.
+func loadTemplate(filePath string) (*template.Template, error) {
+	if filePath == "" {
+		return defaultTemplate, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file %s: %w", filePath, err)
+	}
+	tmpl, err := template.New(filepath.Base(filePath)).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template file %s: %w", filePath, err)
+	}
+	return tmpl, nil
+}
+
+// newTemplateData converts a Report to the data passed to the report template
+func newTemplateData(r Report) TemplateData {
+	data := TemplateData{
+		Title:           title,
+		Note:            note,
+		GeneratedAt:     r.GeneratedAt,
+		Organization:    r.Organization,
+		MultiRepository: r.multiRepository(),
+		Repositories:    []TemplateRepository{},
+		Pricing:         r.Pricing,
+		Forecast:        r.Forecast,
+		Failures:        r.Failures,
+		Billing:         r.Billing,
+		Filtered:        r.Filtered,
+		Excluded:        r.ExcludedWorkflows,
+		report:          r,
+	}
+	if r.Previous != nil {
+		data.PreviousAt = &r.Previous.GeneratedAt
+	}
+
+	var previousTotal BillableTime
+	for _, rbt := range r.Repositories {
+		previous := r.previousWorkflows(rbt.Repository)
+		repository := TemplateRepository{
+			Name:          rbt.Repository,
+			Workflows:     []TemplateWorkflow{},
+			Jobs:          rbt.Jobs,
+			LargerRunners: rbt.LargerRunners,
+			Storage:       rbt.Storage,
+			Excluded:      rbt.ExcludedWorkflows,
+			rbt:           rbt,
+			pricing:       r.Pricing,
+			previous:      previous,
+			sort:          r.Sort,
+			filtered:      r.Filtered,
+		}
+		for _, wbt := range rbt.Workflows.sortBy(r.Sort, r.Pricing) {
+			repository.Workflows = append(repository.Workflows, TemplateWorkflow{
+				ID:                   wbt.ID,
+				Name:                 wbt.Name,
+				DisplayName:          rbt.Workflows.displayName(wbt),
+				Path:                 wbt.Path,
+				State:                wbt.State,
+				TemplateBillableTime: newTemplateBillableTime(wbt.BillableTime, previous[wbt.ID], previous != nil, r.Pricing),
+			})
+		}
+		previousRepositoryTotal := rbt.Workflows.calculatePreviousTotal(previous)
+		previousTotal = previousTotal.add(previousRepositoryTotal)
+		repository.Total = newTemplateBillableTime(rbt.Workflows.calculateTotal(), previousRepositoryTotal, previous != nil, r.Pricing)
+		data.Repositories = append(data.Repositories, repository)
+	}
+	data.Total = newTemplateBillableTime(r.calculateTotal(), previousTotal, r.Previous != nil, r.Pricing)
+
+	return data
+}
+
+// newTemplateBillableTime converts a BillableTime to a TemplateBillableTime with the change since the previous one if hasPrevious is set
+func newTemplateBillableTime(b, previous BillableTime, hasPrevious bool, pricing Pricing) TemplateBillableTime {
+	tbt := TemplateBillableTime{
+		Ubuntu:   b.Ubuntu,
+		Windows:  b.Windows,
+		Macos:    b.Macos,
+		Weighted: pricing.weightedMinutes(b),
+		CostUSD:  pricing.cost(b),
+	}
+	if hasPrevious {
+		change := b.sub(previous)
+		tbt.Change = &change
+	}
+	return tbt
+}
+
+// BillingMarkdown renders the billing summary section with the heading level (e.g. "#"), or nothing without a billing summary
+func (d TemplateData) BillingMarkdown(heading string) string {
+	if d.Billing == nil {
+		return ""
+	}
+	return d.Billing.generateMarkdown(heading)
+}
+
+// OrganizationTotalTable renders the table of the totals for each repository with a grand total row
+func (d TemplateData) OrganizationTotalTable() string {
+	return d.report.generateOrganizationTotalTable()
+}
+
+// FailuresMarkdown renders the section of the workflows and repositories that could not be fetched, or nothing without failures
+func (d TemplateData) FailuresMarkdown() string {
+	if len(d.Failures) == 0 {
+		return ""
+	}
+	return generateFailuresMarkdown(d.Failures)
+}
+
+// ForecastMarkdown renders the forecast section, or nothing without a forecast
+func (d TemplateData) ForecastMarkdown() string {
+	if d.Forecast == nil {
+		return ""
+	}
+	return d.Forecast.generateMarkdown(d.Pricing)
+}
+
+// WorkflowTable renders the table of the billable times for each workflow with a total row, sorted and limited as specified by --sort and --top.
+// If the workflows are filtered, the total row is labelled as filtered and followed by the number of the excluded workflows.
+func (t TemplateRepository) WorkflowTable() string {
+	table := t.rbt.Workflows.generateMarkdownTable(t.pricing, t.previous, t.sort, filteredTitle("Total", t.filtered))
+	if t.Excluded > 0 {
+		table += filterNote(t.Excluded)
+	}
+	return table
+}
+
+// JobsMarkdown renders the top jobs section with the heading level (e.g. "##"), or nothing without jobs
+func (t TemplateRepository) JobsMarkdown(heading string) string {
+	if len(t.Jobs) == 0 {
+		return ""
+	}
+	return generateJobsMarkdown(heading, t.Jobs)
+}
+
+// LargerRunnersMarkdown renders the larger runners section with the heading level (e.g. "##"), or nothing without larger runners
+func (t TemplateRepository) LargerRunnersMarkdown(heading string) string {
+	if len(t.LargerRunners) == 0 {
+		return ""
+	}
+	return generateLargerRunnersMarkdown(heading, t.LargerRunners, t.pricing)
+}
+
+// StorageMarkdown renders the storage section with the heading level (e.g. "##"), or nothing without storage
+func (t TemplateRepository) StorageMarkdown(heading string) string {
+	if t.Storage == nil {
+		return ""
+	}
+	return generateStorageMarkdown(heading, *t.Storage, t.pricing)
+}
+
+// executeTemplate renders the report with the template
+func (r Report) executeTemplate(tmpl *template.Template) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, newTemplateData(r)); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,141 @@
+package bills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_loadTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		filePath string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			filePath: "",
+			wantName: "default",
+			wantErr:  false,
+		},
+		{
+			name:     "custom",
+			filePath: writeFile("custom.md.tmpl", `# {{ .Title }} {{ cost .Total.CostUSD }}`),
+			wantName: "custom.md.tmpl",
+			wantErr:  false,
+		},
+		{
+			name:     "unknown function",
+			filePath: writeFile("unknown.md.tmpl", `{{ unknown .Title }}`),
+			wantErr:  true,
+		},
+		{
+			name:     "not found",
+			filePath: filepath.Join(tempDir, "notfound.md.tmpl"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadTemplate(tt.filePath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Name() != tt.wantName {
+				t.Errorf("loadTemplate() name = %v, want %v", got.Name(), tt.wantName)
+			}
+		})
+	}
+}
+
+func TestReport_executeTemplate(t *testing.T) {
+	report := Report{
+		Pricing:     DefaultPricing,
+		GeneratedAt: time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC),
+		Repositories: []RepositoryBillableTime{
+			{
+				Repository: "owner/repo",
+				Workflows: WorkflowBillableTimes{
+					{ID: 2, Name: "Release", Path: ".github/workflows/release.yml", BillableTime: BillableTime{Macos: 3}},
+					{ID: 1, Name: "CI", Path: ".github/workflows/ci.yml", BillableTime: BillableTime{Ubuntu: 100, Windows: 10}},
+				},
+			},
+		},
+		Previous: &Snapshot{
+			GeneratedAt: time.Date(2024, 5, 9, 9, 30, 0, 0, time.UTC),
+			Repositories: []SnapshotRepository{
+				{Repository: "owner/repo", Workflows: []SnapshotWorkflow{{ID: 1, Ubuntu: 90, Windows: 10}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "custom layout",
+			template: `## 今月の実行時間 ({{ .GeneratedAt.Format "2006-01-02" }})
+{{ range .Repositories }}{{ .Name }}:
+{{ range .Workflows }}- {{ .DisplayName }}: {{ .Ubuntu }}/{{ .Windows }}/{{ .Macos }} min, {{ minutes .Weighted }} weighted, {{ cost .CostUSD }}{{ with .Change }} (+{{ .Ubuntu }} Ubuntu){{ end }}
+{{ end }}{{ end }}Total: {{ cost .Total.CostUSD }} since {{ .PreviousAt.Format "2006-01-02" }}
+See https://runbook.example.com/actions
+`,
+			want: `## 今月の実行時間 (2024-05-10)
+owner/repo:
+- CI: 100/10/0 min, 120 weighted, $0.96 (+10 Ubuntu)
+- Release: 0/0/3 min, 30 weighted, $0.24 (+0 Ubuntu)
+Total: $1.20 since 2024-05-09
+See https://runbook.example.com/actions
+`,
+		},
+		{
+			name:     "sections",
+			template: `{{ range .Repositories }}{{ .WorkflowTable }}{{ .JobsMarkdown "##" }}{{ end }}{{ .FailuresMarkdown }}{{ .ForecastMarkdown }}`,
+			want: `| Workflow | Ubuntu (min) | Windows (min) | Macos (min) | Weighted (min) | Cost (USD) | Ubuntu (+/-) | Windows (+/-) | Macos (+/-) |
+| --- | --- | --- | --- | --- | --- | --- | --- | --- |
+| CI | 100 | 10 | 0 | 120 | $0.96 | +10 | 0 | 0 |
+| Release | 0 | 0 | 3 | 30 | $0.24 | 0 | 0 | +3 |
+| **Total** | **100** | **10** | **3** | **150** | **$1.20** | **+10** | **0** | **+3** |
+`,
+		},
+		{
+			name:     "execution error",
+			template: `{{ .Unknown }}`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.md.tmpl")
+			if err := os.WriteFile(path, []byte(tt.template), 0o644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			tmpl, err := loadTemplate(path)
+			if err != nil {
+				t.Fatalf("loadTemplate() error = %v", err)
+			}
+
+			got, err := report.executeTemplate(tmpl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Report.executeTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Report.executeTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,3 +1,179 @@
 #!/bin/sh
 
-actbills $@
+if [ -n "$INPUT_CONFIG" ]; then
+	set -- "$@" --config "$INPUT_CONFIG"
+fi
+
+if [ -n "$INPUT_ORG" ]; then
+	set -- "$@" --org "$INPUT_ORG"
+fi
+
+if [ -n "$INPUT_REPOSITORIES" ]; then
+	set -- "$@" --repos "$INPUT_REPOSITORIES"
+fi
+
+if [ -n "$INPUT_PRICING" ]; then
+	set -- "$@" --pricing "$INPUT_PRICING"
+fi
+
+if [ -n "$INPUT_FORMAT" ]; then
+	set -- "$@" --format "$INPUT_FORMAT"
+fi
+
+if [ -n "$INPUT_OUTPUT" ]; then
+	set -- "$@" --output "$INPUT_OUTPUT"
+fi
+
+if [ -n "$INPUT_JSON_REPORT" ]; then
+	set -- "$@" --json-report "$INPUT_JSON_REPORT"
+fi
+
+if [ -n "$INPUT_TEMPLATE" ]; then
+	set -- "$@" --template "$INPUT_TEMPLATE"
+fi
+
+if [ -n "$INPUT_SORT" ]; then
+	set -- "$@" --sort "$INPUT_SORT"
+fi
+
+if [ -n "$INPUT_SORT_ORDER" ]; then
+	set -- "$@" --sort-order "$INPUT_SORT_ORDER"
+fi
+
+if [ -n "$INPUT_TOP" ]; then
+	set -- "$@" --top "$INPUT_TOP"
+fi
+
+if [ -n "$INPUT_INCLUDE_NAMES" ]; then
+	set -- "$@" --include-name "$INPUT_INCLUDE_NAMES"
+fi
+
+if [ -n "$INPUT_EXCLUDE_NAMES" ]; then
+	set -- "$@" --exclude-name "$INPUT_EXCLUDE_NAMES"
+fi
+
+if [ -n "$INPUT_INCLUDE_PATHS" ]; then
+	set -- "$@" --include-path "$INPUT_INCLUDE_PATHS"
+fi
+
+if [ -n "$INPUT_EXCLUDE_PATHS" ]; then
+	set -- "$@" --exclude-path "$INPUT_EXCLUDE_PATHS"
+fi
+
+if [ -n "$INPUT_INCLUDE_STATES" ]; then
+	set -- "$@" --include-state "$INPUT_INCLUDE_STATES"
+fi
+
+if [ -n "$INPUT_EXCLUDE_STATES" ]; then
+	set -- "$@" --exclude-state "$INPUT_EXCLUDE_STATES"
+fi
+
+if [ -n "$INPUT_TOP_JOBS" ]; then
+	set -- "$@" --top-jobs "$INPUT_TOP_JOBS"
+fi
+
+if [ "$INPUT_LARGER_RUNNERS" = "true" ]; then
+	set -- "$@" --larger-runners
+fi
+
+if [ -n "$INPUT_RUNNER_SKUS" ]; then
+	set -- "$@" --runner-sku "$INPUT_RUNNER_SKUS"
+fi
+
+if [ "$INPUT_STORAGE" = "true" ]; then
+	set -- "$@" --storage
+fi
+
+if [ -n "$INPUT_BUDGET" ]; then
+	set -- "$@" --budget "$INPUT_BUDGET"
+fi
+
+if [ "$INPUT_FAIL_ON_BUDGET" = "true" ]; then
+	set -- "$@" --fail-on-budget
+fi
+
+if [ -n "$INPUT_CONCURRENCY" ]; then
+	set -- "$@" --concurrency "$INPUT_CONCURRENCY"
+fi
+
+if [ "$INPUT_COLLECT_ERRORS" = "true" ]; then
+	set -- "$@" --collect-errors
+fi
+
+if [ "$INPUT_TOLERANT" = "true" ]; then
+	set -- "$@" --tolerant
+fi
+
+if [ "$INPUT_FAIL_ON_PARTIAL" = "true" ]; then
+	set -- "$@" --fail-on-partial
+fi
+
+if [ -n "$INPUT_MAX_RETRIES" ]; then
+	set -- "$@" --max-retries "$INPUT_MAX_RETRIES"
+fi
+
+if [ -n "$INPUT_MAX_RATE_LIMIT_WAIT" ]; then
+	set -- "$@" --max-rate-limit-wait "$INPUT_MAX_RATE_LIMIT_WAIT"
+fi
+
+if [ "$INPUT_BILLING_SUMMARY" = "true" ]; then
+	set -- "$@" --billing-summary
+fi
+
+if [ -n "$INPUT_API_URL" ]; then
+	set -- "$@" --api-url "$INPUT_API_URL"
+fi
+
+if [ -n "$INPUT_UPLOAD_URL" ]; then
+	set -- "$@" --upload-url "$INPUT_UPLOAD_URL"
+fi
+
+if [ -n "$INPUT_APP_ID" ]; then
+	set -- "$@" --app-id "$INPUT_APP_ID"
+fi
+
+if [ -n "$INPUT_SNAPSHOT" ]; then
+	set -- "$@" --snapshot "$INPUT_SNAPSHOT"
+fi
+
+if [ -n "$INPUT_SNAPSHOT_KEEP" ]; then
+	set -- "$@" --snapshot-keep "$INPUT_SNAPSHOT_KEEP"
+fi
+
+if [ -n "$INPUT_BILLING_CYCLE_DAY" ]; then
+	set -- "$@" --billing-cycle-day "$INPUT_BILLING_CYCLE_DAY"
+fi
+
+if [ "$INPUT_FORECAST" = "true" ]; then
+	set -- "$@" --forecast
+fi
+
+if [ -n "$INPUT_PLAN" ]; then
+	set -- "$@" --plan "$INPUT_PLAN"
+fi
+
+if [ -n "$INPUT_COMMENT_ON" ]; then
+	set -- "$@" --comment-on "$INPUT_COMMENT_ON"
+fi
+
+if [ "$INPUT_TRACKING_ISSUE" = "true" ]; then
+	set -- "$@" --tracking-issue
+fi
+
+if [ -n "$INPUT_PUBLISH_REPOSITORY" ]; then
+	set -- "$@" --publish-repo "$INPUT_PUBLISH_REPOSITORY"
+fi
+
+if [ -n "$INPUT_SLACK_WEBHOOK_URL" ]; then
+	set -- "$@" --slack-webhook-url "$INPUT_SLACK_WEBHOOK_URL"
+fi
+
+if [ -n "$INPUT_WEBHOOK_URL" ]; then
+	set -- "$@" --webhook-url "$INPUT_WEBHOOK_URL"
+fi
+
+if [ "$INPUT_NOTIFY_DRY_RUN" = "true" ]; then
+	set -- "$@" --notify-dry-run
+fi
+
+actbills "$@"